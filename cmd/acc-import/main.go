@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/devnull-twitch/nyooom-backend/internal/server"
+	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+)
+
+// acc-import creates draft events from ACC results files. Qualifying and race files of the
+// same race weekend should be imported together so the race gets its starting grid.
+func main() {
+	seasonID := flag.Uint64("season", 0, "season of the created events")
+	name := flag.String("name", "", "event name, defaults to the track name")
+	date := flag.Int64("date", 0, "event date as unix timestamp, defaults to now")
+	flag.Parse()
+
+	if flag.NArg() <= 0 {
+		fmt.Fprintln(os.Stderr, "usage: acc-import [-season id] [-name name] [-date unix] results.json...")
+		os.Exit(2)
+	}
+
+	files := make([][]byte, 0, flag.NArg())
+	for _, resultsPath := range flag.Args() {
+		buf, err := os.ReadFile(resultsPath)
+		if err != nil {
+			panic(err)
+		}
+		files = append(files, buf)
+	}
+
+	repo := jsondb.CreateFileDatabase()
+	events, unmatched, err := server.ImportACCResults(repo, files, server.ImportOptions{
+		SeasonID: *seasonID,
+		Name:     *name,
+		Date:     *date,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	if len(unmatched) > 0 {
+		fmt.Fprintln(os.Stderr, "nothing imported, add aliases for these drivers:")
+		for _, driver := range unmatched {
+			fmt.Fprintf(os.Stderr, "  %s: #%d %s %s\n", driver.Session, driver.CarNumber, driver.Name, driver.PlayerID)
+		}
+		os.Exit(1)
+	}
+
+	for _, event := range events {
+		fmt.Printf("created draft event %d %s\n", event.ID, event.Name)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/devnull-twitch/nyooom-backend/internal/server"
+	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+	"github.com/devnull-twitch/nyooom-backend/pkg/telemetry/f1"
+)
+
+// f1-replay reads F1 telemetry captures and creates a draft event for every race session
+// in them. With -dry-run the decoded classifications are printed instead.
+func main() {
+	dryRun := flag.Bool("dry-run", false, "print the decoded classifications without creating events")
+	flag.Parse()
+
+	if flag.NArg() <= 0 {
+		fmt.Fprintln(os.Stderr, "usage: f1-replay [-dry-run] capture...")
+		os.Exit(2)
+	}
+
+	var repo jsondb.JsonDatabase
+	if !*dryRun {
+		repo = jsondb.CreateFileDatabase()
+	}
+
+	for _, capturePath := range flag.Args() {
+		captureFile, err := os.Open(capturePath)
+		if err != nil {
+			panic(err)
+		}
+
+		if *dryRun {
+			err = printClassifications(captureFile)
+		} else {
+			var events []*jsondb.RaceEvent
+			events, err = server.ReplayF1Capture(repo, captureFile)
+			for _, event := range events {
+				fmt.Printf("%s: created draft event %d %s\n", capturePath, event.ID, event.Name)
+			}
+		}
+		captureFile.Close()
+
+		if err != nil {
+			panic(fmt.Errorf("unable to replay %s: %w", capturePath, err))
+		}
+	}
+}
+
+func printClassifications(captureFile *os.File) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	var encodeErr error
+	collector := f1.NewCollector(func(classification f1.Classification) {
+		if encodeErr == nil {
+			encodeErr = encoder.Encode(classification)
+		}
+	})
+
+	err := f1.ReadCapture(captureFile, func(packet []byte) error {
+		if err := collector.Handle(packet); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		return encodeErr
+	})
+	if err != nil {
+		return err
+	}
+
+	return encodeErr
+}
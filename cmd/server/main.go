@@ -1,12 +1,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
 
 	"github.com/devnull-twitch/nyooom-backend/internal/server"
 	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+	"github.com/devnull-twitch/nyooom-backend/pkg/telemetry/f1"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -31,6 +33,33 @@ func main() {
 		editors = append(editors, &server.EditorLogin{Username: credentialParts[0], Password: credentialParts[1]})
 	}
 	editorCheckMW := server.GetEditorMiddleware(editors)
+	liveSessions := server.NewLiveSessionStore()
+
+	assetDir := os.Getenv("ASSET_DIR")
+	if assetDir == "" {
+		assetDir = "assets"
+	}
+	if err := os.MkdirAll(assetDir, os.ModePerm); err != nil {
+		panic(err)
+	}
+
+	if telemetryAddress := os.Getenv("F1_TELEMETRY_ADDRESS"); telemetryAddress != "" {
+		var capture *f1.CaptureWriter
+		if capturePath := os.Getenv("F1_TELEMETRY_CAPTURE"); capturePath != "" {
+			captureFile, err := os.OpenFile(capturePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+			if err != nil {
+				panic(err)
+			}
+			defer captureFile.Close()
+			capture = f1.NewCaptureWriter(captureFile)
+		}
+
+		go func() {
+			if err := server.ListenF1Telemetry(context.Background(), repo, telemetryAddress, capture); err != nil {
+				panic(err)
+			}
+		}()
+	}
 
 	r := gin.Default()
 
@@ -39,21 +68,75 @@ func main() {
 	corsConfig.AddAllowHeaders("Authorization")
 	r.Use(cors.New(corsConfig))
 
+	r.Static("/assets", assetDir)
+
 	r.GET("/user-check", editorCheckMW, server.GetNoopHandler())
 
 	r.GET("/team", server.GetTeamsHandler(repo))
 	r.GET("/team/:team_id", server.GetTeamHandler(repo))
 	r.GET("/race", server.GetEventsHandler(repo))
 	r.GET("/race/latest", server.GetLatestEventHandler(repo))
+	r.GET("/race/next", server.GetNextEventHandler(repo))
 	r.GET("/race/:race_id", server.GetEventHandler(repo))
+	r.GET("/race/:race_id/live", server.GetLiveSessionHandler(repo, liveSessions))
+	r.GET("/race/:race_id/strategy", server.GetEventStrategyHandler(repo))
+	r.GET("/race/:race_id/report", server.GetReportHandler(repo))
+	r.GET("/report", server.GetReportFeedHandler(repo))
+	r.GET("/race/:race_id/prediction", server.GetEventPredictionsHandler(repo))
+	r.POST("/race/:race_id/prediction", server.SubmitPredictionHandler(repo))
+	r.GET("/prediction/leaderboard", server.GetPredictionLeaderboardHandler(repo))
+	r.GET("/driver/:driver_id/stats", server.GetDriverStatsHandler(repo))
+	r.GET("/compare", server.GetCompareHandler(repo))
+	r.GET("/pit-stops", server.GetPitStopStatsHandler(repo))
+	r.GET("/incident", server.GetIncidentsHandler(repo))
+	r.GET("/incident/:incident_id", server.GetIncidentHandler(repo))
+	r.GET("/season", server.GetSeasonsHandler(repo))
+	r.GET("/season/:season_id", server.GetSeasonHandler(repo))
+	r.GET("/standings", server.GetStandingsHandler(repo))
+	r.GET("/standings/progression", server.GetStandingsProgressionHandler(repo))
+	r.GET("/standings/scenarios", server.GetStandingsScenariosHandler(repo))
+	r.GET("/settings", server.GetSettingsHandler(repo))
 
 	r.POST("/team", editorCheckMW, server.AddTeamHandler(repo))
 	r.PUT("/team/:team_id", editorCheckMW, server.UpdateTeamHandler(repo))
 	r.DELETE("/team/:team_id", editorCheckMW, server.DeleteTeamHandler(repo))
-	r.PUT("/team/:team_id/:driver_id", editorCheckMW, func(ctx *gin.Context) {})
+	r.POST("/team/:team_id/driver", editorCheckMW, server.AddDriverHandler(repo))
+	r.PUT("/team/:team_id/:driver_id", editorCheckMW, server.UpdateDriverHandler(repo))
+	r.POST("/team/:team_id/logo", editorCheckMW, server.UploadTeamLogoHandler(repo, assetDir))
+	r.POST("/team/:team_id/:driver_id/portrait", editorCheckMW, server.UploadDriverPortraitHandler(repo, assetDir))
 	r.POST("/race", editorCheckMW, server.CreateRaceEventHandler(repo))
 	r.PUT("/race/:race_id", editorCheckMW, server.UpdateRaceEventHandler(repo))
 	r.DELETE("/race/:race_id", editorCheckMW, server.DeleteRaceEventHandler(repo))
+	r.PUT("/race/:race_id/status", editorCheckMW, server.UpdateEventStatusHandler(repo))
+	r.POST("/race/:race_id/grid", editorCheckMW, server.GenerateGridHandler(repo))
+	r.POST("/race/:race_id/results/csv", editorCheckMW, server.ImportCSVResultsHandler(repo))
+	r.POST("/race/:race_id/results/csv/preview", editorCheckMW, server.PreviewCSVResultsHandler(repo))
+	r.POST("/race/:race_id/live", editorCheckMW, server.StartLiveSessionHandler(repo, liveSessions))
+	r.PUT("/race/:race_id/live", editorCheckMW, server.UpdateLiveSessionHandler(repo, liveSessions))
+	r.DELETE("/race/:race_id/live", editorCheckMW, server.AbortLiveSessionHandler(repo, liveSessions))
+	r.POST("/race/:race_id/live/finalize", editorCheckMW, server.FinalizeLiveSessionHandler(repo, liveSessions))
+	r.GET("/race/:race_id/report/draft", editorCheckMW, server.GetDraftReportHandler(repo))
+	r.PUT("/race/:race_id/report", editorCheckMW, server.SaveReportHandler(repo))
+	r.DELETE("/race/:race_id/report", editorCheckMW, server.DeleteReportHandler(repo))
+	r.DELETE("/prediction/:prediction_id", editorCheckMW, server.DeletePredictionHandler(repo))
+	r.POST("/incident", editorCheckMW, server.AddIncidentHandler(repo))
+	r.PUT("/incident/:incident_id", editorCheckMW, server.UpdateIncidentHandler(repo))
+	r.DELETE("/incident/:incident_id", editorCheckMW, server.DeleteIncidentHandler(repo))
+	r.POST("/season", editorCheckMW, server.AddSeasonHandler(repo))
+	r.PUT("/season/:season_id", editorCheckMW, server.UpdateSeasonHandler(repo))
+	r.DELETE("/season/:season_id", editorCheckMW, server.DeleteSeasonHandler(repo))
+	r.POST("/season/:season_id/promotion", editorCheckMW, server.EndSeasonHandler(repo))
+	r.PUT("/settings", editorCheckMW, server.UpdateSettingsHandler(repo))
+	r.GET("/standings/simulation", editorCheckMW, server.GetStandingsSimulationHandler(repo))
+	r.GET("/alias", editorCheckMW, server.GetAliasesHandler(repo))
+	r.POST("/alias", editorCheckMW, server.AddAliasHandler(repo))
+	r.DELETE("/alias/:alias_id", editorCheckMW, server.DeleteAliasHandler(repo))
+	r.POST("/import/acc", editorCheckMW, server.ImportACCResultsHandler(repo))
+	r.POST("/import/iracing", editorCheckMW, server.ImportIRacingResultsHandler(repo))
+	r.POST("/import/rf2", editorCheckMW, server.ImportRF2ResultsHandler(repo))
+	r.POST("/import/rf2/preview", editorCheckMW, server.PreviewRF2ResultsHandler(repo))
+	r.GET("/bundle", editorCheckMW, server.ExportBundleHandler(repo, assetDir))
+	r.POST("/bundle", editorCheckMW, server.ImportBundleHandler(repo, assetDir))
 
 	r.Run(os.Getenv("WEBSERVER_ADDRESS"))
 }
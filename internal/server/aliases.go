@@ -0,0 +1,290 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func GetAliasesHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		aliases, err := repo.ListAliases()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read aliases")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		_, driverNameMap, err := buildNameMaps(repo)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to generate name maps")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, convertAliasesToResponse(aliases, driverNameMap))
+	}
+}
+
+func AddAliasHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		newAlias := &jsondb.Alias{}
+
+		if err := ctx.BindJSON(newAlias); err != nil {
+			logrus.WithError(err).Warn("unable to get user input for new alias")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		_, driverNameMap, err := buildNameMaps(repo)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to generate name maps")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		if err := normalizeAlias(newAlias, driverNameMap); err != nil {
+			logrus.WithError(err).Warn("invalid alias")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		if err := repo.AddAlias(newAlias); err != nil {
+			logrus.WithError(err).Warn("unable to add alias")
+			ctx.AbortWithStatus(http.StatusConflict)
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, newAlias)
+	}
+}
+
+func DeleteAliasHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		aliasID, err := strconv.Atoi(ctx.Param("alias_id"))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		if err := repo.DeleteAlias(uint64(aliasID)); err != nil {
+			logrus.WithError(err).Warn("unable to delete alias")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		ctx.Status(http.StatusOK)
+	}
+}
+
+func normalizeAlias(alias *jsondb.Alias, driverNameMap map[uint64]string) error {
+	if alias.Type < jsondb.SteamAliasType || alias.Type > jsondb.IRacingAliasType {
+		return fmt.Errorf("unknown alias type %d", alias.Type)
+	}
+	if _, ok := driverNameMap[alias.DriverID]; !ok {
+		return fmt.Errorf("unknown driver %d", alias.DriverID)
+	}
+
+	alias.Value = strings.TrimSpace(alias.Value)
+	if alias.Type == jsondb.NameAliasType {
+		alias.Value = strings.ToLower(alias.Value)
+	}
+	if alias.Value == "" {
+		return fmt.Errorf("alias value is missing")
+	}
+
+	return nil
+}
+
+// driverMatcher finds the drivers behind the identities of imported results files.
+type driverMatcher struct {
+	aliases map[jsondb.AliasType]map[string]uint64
+	names   map[string]uint64
+	// fuzzyNames holds the lower case names of all drivers including ambiguous ones.
+	fuzzyNames map[uint64]string
+}
+
+// maxFuzzyDistance is the most edits a name may need to still match a driver.
+const maxFuzzyDistance = 2
+
+// buildDriverMatcher indexes all aliases and the lower case driver names. Driver names
+// used by more than one driver can only be matched through a name alias.
+func buildDriverMatcher(aliases []jsondb.Alias, teams []jsondb.Team) *driverMatcher {
+	matcher := &driverMatcher{
+		aliases:    make(map[jsondb.AliasType]map[string]uint64),
+		names:      make(map[string]uint64),
+		fuzzyNames: make(map[uint64]string),
+	}
+
+	for _, alias := range aliases {
+		if matcher.aliases[alias.Type] == nil {
+			matcher.aliases[alias.Type] = make(map[string]uint64)
+		}
+		matcher.aliases[alias.Type][alias.Value] = alias.DriverID
+	}
+
+	ambiguous := make(map[string]bool)
+	for _, t := range teams {
+		for _, d := range t.Drivers {
+			name := strings.ToLower(strings.TrimSpace(d.Name))
+			if _, ok := matcher.names[name]; ok {
+				ambiguous[name] = true
+			}
+			matcher.names[name] = d.ID
+			matcher.fuzzyNames[d.ID] = name
+		}
+	}
+	for name := range ambiguous {
+		delete(matcher.names, name)
+	}
+
+	return matcher
+}
+
+func (m *driverMatcher) matchAlias(aliasType jsondb.AliasType, value string) (uint64, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	driverID, ok := m.aliases[aliasType][value]
+	return driverID, ok
+}
+
+// matchName checks the name aliases before the driver names.
+func (m *driverMatcher) matchName(name string) (uint64, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if driverID, ok := m.matchAlias(jsondb.NameAliasType, name); ok {
+		return driverID, true
+	}
+	if name == "" {
+		return 0, false
+	}
+
+	driverID, ok := m.names[name]
+	return driverID, ok
+}
+
+// matchFuzzy finds the driver for a misspelled or partial name. A name matches if it is a
+// unique part of a driver name or if it is only a few edits away from the name or a part of it.
+// Names closer to several drivers than to a single one are not matched.
+func (m *driverMatcher) matchFuzzy(name string) (uint64, bool) {
+	words := strings.Fields(strings.ToLower(name))
+	key := fuzzyKey(name)
+	if key == "" {
+		return 0, false
+	}
+
+	partMatches := make([]uint64, 0)
+	for driverID, driverName := range m.fuzzyNames {
+		if containsAllWords(strings.Fields(driverName), words) {
+			partMatches = append(partMatches, driverID)
+		}
+	}
+	if len(partMatches) == 1 {
+		return partMatches[0], true
+	}
+	if len(partMatches) > 1 {
+		return 0, false
+	}
+
+	var (
+		bestID       uint64
+		bestDistance = maxFuzzyDistance + 1
+		tied         bool
+	)
+	for driverID, driverName := range m.fuzzyNames {
+		distance := maxFuzzyDistance + 1
+		for _, part := range append([]string{driverName}, strings.Fields(driverName)...) {
+			partKey := fuzzyKey(part)
+			// short names need to be closer to count as the same name
+			if d := levenshtein(key, partKey); d < distance && d*4 <= len([]rune(partKey)) {
+				distance = d
+			}
+		}
+		if distance > maxFuzzyDistance {
+			continue
+		}
+		if distance < bestDistance {
+			bestID = driverID
+			bestDistance = distance
+			tied = false
+		} else if distance == bestDistance {
+			tied = true
+		}
+	}
+
+	if bestDistance > maxFuzzyDistance || tied {
+		return 0, false
+	}
+
+	return bestID, true
+}
+
+func containsAllWords(words []string, search []string) bool {
+	if len(search) <= 0 {
+		return false
+	}
+
+	for _, s := range search {
+		found := false
+		for _, w := range words {
+			if fuzzyKey(w) == fuzzyKey(s) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func fuzzyKey(name string) string {
+	builder := strings.Builder{}
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			builder.WriteRune(r)
+		}
+	}
+
+	return builder.String()
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(minInt(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,180 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	maxAssetSize = 2 << 20
+	// the multipart envelope around the image is allowed on top of the image size
+	maxAssetRequestSize = maxAssetSize + 64<<10
+	assetFormField      = "image"
+)
+
+// assetExtensions are the accepted image types by their sniffed content type.
+var assetExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+func UploadTeamLogoHandler(repo jsondb.JsonDatabase, assetDir string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		teamID, err := strconv.Atoi(ctx.Param("team_id"))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		existing, err := repo.GetTeam(uint64(teamID))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		fileName, ok := storeAsset(ctx, assetDir, fmt.Sprintf("team-%d-logo", existing.ID))
+		if !ok {
+			return
+		}
+
+		previous := existing.Logo
+		existing.Logo = fileName
+		if err := repo.UpdateTeam(existing); err != nil {
+			logrus.WithError(err).Warn("unable to update team with new logo")
+			removeAsset(assetDir, fileName)
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		removeAsset(assetDir, previous)
+
+		ctx.JSON(http.StatusOK, convertTeamBranding(*existing))
+	}
+}
+
+func UploadDriverPortraitHandler(repo jsondb.JsonDatabase, assetDir string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		teamID, err := strconv.Atoi(ctx.Param("team_id"))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		driverID, err := strconv.Atoi(ctx.Param("driver_id"))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		existing, err := repo.GetTeam(uint64(teamID))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		driverIndex := -1
+		for index, d := range existing.Drivers {
+			if d.ID == uint64(driverID) {
+				driverIndex = index
+				break
+			}
+		}
+		if driverIndex < 0 {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		fileName, ok := storeAsset(ctx, assetDir, fmt.Sprintf("driver-%d-portrait", driverID))
+		if !ok {
+			return
+		}
+
+		previous := existing.Drivers[driverIndex].Portrait
+		existing.Drivers[driverIndex].Portrait = fileName
+		if err := repo.UpdateTeam(existing); err != nil {
+			logrus.WithError(err).Warn("unable to update driver with new portrait")
+			removeAsset(assetDir, fileName)
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		removeAsset(assetDir, previous)
+
+		ctx.JSON(http.StatusOK, convertDriverBranding(existing.Drivers[driverIndex]))
+	}
+}
+
+// storeAsset validates the uploaded image and writes it into the asset directory. The file
+// name contains the upload time so clients do not show a cached old image after a change.
+// The request is aborted if ok is false.
+func storeAsset(ctx *gin.Context, assetDir string, prefix string) (fileName string, ok bool) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxAssetRequestSize)
+	fileHeader, err := ctx.FormFile(assetFormField)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			ctx.AbortWithStatus(http.StatusRequestEntityTooLarge)
+			return
+		}
+		logrus.WithError(err).Warn("unable to get uploaded image")
+		ctx.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	if fileHeader.Size > maxAssetSize {
+		ctx.AbortWithStatus(http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		logrus.WithError(err).Warn("unable to open uploaded image")
+		ctx.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		logrus.WithError(err).Warn("unable to read uploaded image")
+		ctx.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	// the declared content type of the upload is ignored as only the content itself can be trusted
+	extension, known := assetExtensions[http.DetectContentType(content)]
+	if !known {
+		ctx.AbortWithStatus(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	fileName = fmt.Sprintf("%s-%d%s", prefix, time.Now().UnixNano(), extension)
+	if err := os.WriteFile(filepath.Join(assetDir, fileName), content, 0644); err != nil {
+		logrus.WithError(err).Warn("unable to write uploaded image")
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	ok = true
+	return
+}
+
+func removeAsset(assetDir string, fileName string) {
+	if fileName == "" {
+		return
+	}
+
+	if err := os.Remove(filepath.Join(assetDir, filepath.Base(fileName))); err != nil && !errors.Is(err, os.ErrNotExist) {
+		logrus.WithError(err).Warn("unable to remove replaced asset")
+	}
+}
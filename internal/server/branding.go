@@ -0,0 +1,113 @@
+package server
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+)
+
+const (
+	assetURLPrefix     = "/assets/"
+	maxShortNameLength = 16
+	maxCarNumber       = 999
+)
+
+var (
+	teamColorRegex    = regexp.MustCompile(`^#[0-9A-F]{6}$`)
+	abbreviationRegex = regexp.MustCompile(`^[A-Z]{3}$`)
+	nationalityRegex  = regexp.MustCompile(`^[A-Z]{2,3}$`)
+)
+
+type brandingMaps struct {
+	Teams   map[uint64]teamBrandingResponse
+	Drivers map[uint64]driverBrandingResponse
+}
+
+func buildBrandingMaps(repo jsondb.JsonDatabase) (brandingMaps, error) {
+	branding := brandingMaps{
+		Teams:   make(map[uint64]teamBrandingResponse),
+		Drivers: make(map[uint64]driverBrandingResponse),
+	}
+
+	teams, err := repo.ListTeams()
+	if err != nil {
+		return branding, err
+	}
+
+	for _, t := range teams {
+		branding.Teams[t.ID] = convertTeamBranding(t)
+		for _, d := range t.Drivers {
+			branding.Drivers[d.ID] = convertDriverBranding(d)
+		}
+	}
+
+	return branding, nil
+}
+
+func assetURL(fileName string) string {
+	if fileName == "" {
+		return ""
+	}
+
+	return assetURLPrefix + fileName
+}
+
+func convertTeamBranding(t jsondb.Team) teamBrandingResponse {
+	return teamBrandingResponse{
+		ShortName: t.ShortName,
+		Color:     t.Color,
+		LogoURL:   assetURL(t.Logo),
+	}
+}
+
+func convertDriverBranding(d jsondb.Driver) driverBrandingResponse {
+	return driverBrandingResponse{
+		Abbreviation: d.Abbreviation,
+		Nationality:  d.Nationality,
+		CarNumber:    d.CarNumber,
+		PortraitURL:  assetURL(d.Portrait),
+	}
+}
+
+// normalizeTeamBranding upper cases the color and validates the branding fields editors can set.
+func normalizeTeamBranding(t *jsondb.Team) error {
+	t.ShortName = strings.TrimSpace(t.ShortName)
+	if len(t.ShortName) > maxShortNameLength {
+		return fmt.Errorf("short name is longer than %d characters", maxShortNameLength)
+	}
+
+	t.Color = strings.ToUpper(strings.TrimSpace(t.Color))
+	if t.Color != "" && !teamColorRegex.MatchString(t.Color) {
+		return fmt.Errorf("color %s is not in #RRGGBB format", t.Color)
+	}
+
+	for index := range t.Drivers {
+		if err := normalizeDriverBranding(&t.Drivers[index]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// normalizeDriverBranding upper cases abbreviation and nationality and validates the branding
+// fields editors can set. Nationalities are ISO 3166 country codes.
+func normalizeDriverBranding(d *jsondb.Driver) error {
+	d.Abbreviation = strings.ToUpper(strings.TrimSpace(d.Abbreviation))
+	if d.Abbreviation != "" && !abbreviationRegex.MatchString(d.Abbreviation) {
+		return fmt.Errorf("abbreviation %s is not a three letter code", d.Abbreviation)
+	}
+
+	d.Nationality = strings.ToUpper(strings.TrimSpace(d.Nationality))
+	if d.Nationality != "" && !nationalityRegex.MatchString(d.Nationality) {
+		return fmt.Errorf("nationality %s is not a country code", d.Nationality)
+	}
+
+	if d.CarNumber > maxCarNumber {
+		return fmt.Errorf("car number %d is above %d", d.CarNumber, maxCarNumber)
+	}
+
+	return nil
+}
@@ -0,0 +1,481 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/devnull-twitch/nyooom-backend/pkg/bundle"
+	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	maxBundleRequestSize = 256 << 20
+
+	restoreBundleMode = "restore"
+	mergeBundleMode   = "merge"
+
+	defaultMergedSeasonName = "Imported season"
+)
+
+// bundleMergeOptions select the season of the bundle that is merged and name the new season.
+// Without a source season a bundle with a single season merges that season and a bundle
+// without seasons merges all of its events.
+type bundleMergeOptions struct {
+	SourceSeasonID uint64
+	SeasonName     string
+}
+
+// ExportBundleHandler downloads all teams, events, seasons, incidents, reports, aliases,
+// settings and the uploaded images as a single zip file.
+func ExportBundleHandler(repo jsondb.JsonDatabase, assetDir string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		snapshot, err := repo.Snapshot()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read database snapshot")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		now := time.Now()
+		buf := &bytes.Buffer{}
+		if err := bundle.Write(buf, snapshot, readBundleAssets(assetDir, snapshot.Teams.Teams), now); err != nil {
+			logrus.WithError(err).Warn("unable to write bundle")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("nyooom-%s.zip", now.UTC().Format("20060102-150405"))))
+		ctx.Data(http.StatusOK, "application/zip", buf.Bytes())
+	}
+}
+
+// ImportBundleHandler verifies an uploaded bundle and either restores it into an empty
+// database or merges it into a new season with new IDs for everything it contains.
+func ImportBundleHandler(repo jsondb.JsonDatabase, assetDir string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxBundleRequestSize)
+
+		files, ok := readImportFiles(ctx)
+		if !ok {
+			return
+		}
+		if len(files) != 1 {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		b, err := bundle.Read(files[0])
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read bundle")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		switch ctx.PostForm("mode") {
+		case "", restoreBundleMode:
+			if err := repo.Restore(&b.Snapshot); err != nil {
+				if errors.Is(err, jsondb.ErrDatabaseNotEmpty) {
+					ctx.AbortWithStatus(http.StatusConflict)
+					return
+				}
+				logrus.WithError(err).Warn("unable to restore bundle")
+				ctx.AbortWithStatus(http.StatusInternalServerError)
+				return
+			}
+
+			warnings := make([]string, 0)
+			for name, content := range b.Assets {
+				if err := os.WriteFile(filepath.Join(assetDir, name), content, 0644); err != nil {
+					logrus.WithError(err).Warn("unable to write restored asset")
+					warnings = append(warnings, fmt.Sprintf("unable to restore image %s", name))
+				}
+			}
+
+			ctx.JSON(http.StatusOK, convertRestoredBundleToResponse(b, warnings))
+
+		case mergeBundleMode:
+			options := bundleMergeOptions{SeasonName: ctx.PostForm("season_name")}
+			if seasonParam := ctx.PostForm("source_season_id"); seasonParam != "" {
+				seasonID, err := strconv.Atoi(seasonParam)
+				if err != nil {
+					ctx.AbortWithStatus(http.StatusBadRequest)
+					return
+				}
+				options.SourceSeasonID = uint64(seasonID)
+			}
+
+			source, events, err := selectMergedEvents(&b.Snapshot, options)
+			if err != nil {
+				logrus.WithError(err).Warn("unable to select bundle season")
+				ctx.AbortWithStatus(http.StatusBadRequest)
+				return
+			}
+			if err := validateBundleReferences(&b.Snapshot, source, events); err != nil {
+				logrus.WithError(err).Warn("invalid bundle")
+				ctx.AbortWithStatus(http.StatusBadRequest)
+				return
+			}
+
+			resp, err := mergeBundle(repo, assetDir, b, source, events, options)
+			if err != nil {
+				logrus.WithError(err).Warn("unable to merge bundle")
+				ctx.AbortWithStatus(http.StatusInternalServerError)
+				return
+			}
+
+			ctx.JSON(http.StatusOK, resp)
+
+		default:
+			ctx.AbortWithStatus(http.StatusBadRequest)
+		}
+	}
+}
+
+// readBundleAssets loads the images referenced by teams and drivers. Missing files are left
+// out so a broken image does not block the export.
+func readBundleAssets(assetDir string, teams []jsondb.Team) map[string][]byte {
+	names := make([]string, 0)
+	for _, t := range teams {
+		names = append(names, t.Logo)
+		for _, d := range t.Drivers {
+			names = append(names, d.Portrait)
+		}
+	}
+
+	assets := make(map[string][]byte)
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(assetDir, filepath.Base(name)))
+		if err != nil {
+			logrus.WithError(err).WithField("asset", name).Warn("unable to read asset for bundle")
+			continue
+		}
+		assets[filepath.Base(name)] = content
+	}
+
+	return assets
+}
+
+func selectMergedEvents(snapshot *jsondb.Snapshot, options bundleMergeOptions) (*jsondb.Season, []jsondb.RaceEvent, error) {
+	var source *jsondb.Season
+	switch {
+	case options.SourceSeasonID != 0:
+		for index := range snapshot.Seasons.Seasons {
+			if snapshot.Seasons.Seasons[index].ID == options.SourceSeasonID {
+				source = &snapshot.Seasons.Seasons[index]
+			}
+		}
+		if source == nil {
+			return nil, nil, fmt.Errorf("bundle has no season %d", options.SourceSeasonID)
+		}
+	case len(snapshot.Seasons.Seasons) == 1:
+		source = &snapshot.Seasons.Seasons[0]
+	case len(snapshot.Seasons.Seasons) > 1:
+		return nil, nil, fmt.Errorf("bundle has %d seasons and needs a source season", len(snapshot.Seasons.Seasons))
+	}
+
+	events := filterEvents(snapshot.Events.Events, func(e jsondb.RaceEvent) bool {
+		return source == nil || e.SeasonID == source.ID
+	})
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].ID < events[j].ID
+	})
+
+	return source, events, nil
+}
+
+// validateBundleReferences makes sure every driver and team used by the merged data is part
+// of the bundle before anything is stored.
+func validateBundleReferences(snapshot *jsondb.Snapshot, source *jsondb.Season, events []jsondb.RaceEvent) error {
+	teamIDs := make(map[uint64]bool)
+	driverIDs := make(map[uint64]bool)
+	for _, t := range snapshot.Teams.Teams {
+		teamIDs[t.ID] = true
+		for _, d := range t.Drivers {
+			driverIDs[d.ID] = true
+		}
+	}
+
+	checkDrivers := func(context string, ids ...uint64) error {
+		for _, id := range ids {
+			if !driverIDs[id] {
+				return fmt.Errorf("%s references unknown driver %d", context, id)
+			}
+		}
+		return nil
+	}
+
+	if source != nil {
+		for _, entry := range source.ClassEntries {
+			if err := checkDrivers("season class entries", entry.DriverID); err != nil {
+				return err
+			}
+		}
+		for _, division := range source.Divisions {
+			if err := checkDrivers("season divisions", division.DriverIDs...); err != nil {
+				return err
+			}
+		}
+	}
+
+	eventIDs := make(map[uint64]bool)
+	for _, e := range events {
+		eventIDs[e.ID] = true
+		context := fmt.Sprintf("event %d", e.ID)
+		for _, positions := range [][]jsondb.RacePosition{e.StartingGrid, e.Results} {
+			for _, position := range positions {
+				if err := checkDrivers(context, position.DriverID); err != nil {
+					return err
+				}
+				if !teamIDs[position.TeamID] {
+					return fmt.Errorf("%s references unknown team %d", context, position.TeamID)
+				}
+			}
+		}
+		for _, lineup := range e.Lineups {
+			if !teamIDs[lineup.TeamID] {
+				return fmt.Errorf("%s references unknown team %d", context, lineup.TeamID)
+			}
+			if err := checkDrivers(context, lineup.DriverIDs...); err != nil {
+				return err
+			}
+		}
+		for _, entry := range e.ClassEntries {
+			if err := checkDrivers(context, entry.DriverID); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, incident := range snapshot.Incidents.Incidents {
+		if !eventIDs[incident.EventID] {
+			continue
+		}
+		if err := checkDrivers(fmt.Sprintf("incident %d", incident.ID), incident.DriverIDs...); err != nil {
+			return err
+		}
+	}
+
+	for _, alias := range snapshot.Aliases.Aliases {
+		if err := checkDrivers(fmt.Sprintf("alias %d", alias.ID), alias.DriverID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mergeBundle adds all teams and drivers of the bundle as new teams and drivers and the
+// selected events with their incidents and reports to a new season. Aliases that are already
+// taken are skipped with a warning. Settings of the bundle are ignored. Everything is stored
+// with a single write of the database.
+func mergeBundle(
+	repo jsondb.JsonDatabase,
+	assetDir string,
+	b *bundle.Bundle,
+	source *jsondb.Season,
+	events []jsondb.RaceEvent,
+	options bundleMergeOptions,
+) (*bundleImportResponse, error) {
+	resp := &bundleImportResponse{
+		Mode:     mergeBundleMode,
+		Warnings: make([]string, 0),
+	}
+
+	// images are written before the database so a failed merge removes them again
+	assetFiles := make([]string, 0)
+	err := repo.Merge(func(current *jsondb.Snapshot) error {
+		teamIDs := make(map[uint64]uint64)
+		driverIDs := make(map[uint64]uint64)
+		for _, bundleTeam := range b.Snapshot.Teams.Teams {
+			newTeam := bundleTeam
+			newTeam.Logo = ""
+			newTeam.Drivers = make([]jsondb.Driver, len(bundleTeam.Drivers))
+			copy(newTeam.Drivers, bundleTeam.Drivers)
+			for index := range newTeam.Drivers {
+				newTeam.Drivers[index].Portrait = ""
+			}
+
+			current.Teams.Add(&newTeam)
+			teamIDs[bundleTeam.ID] = newTeam.ID
+			for index, d := range bundleTeam.Drivers {
+				driverIDs[d.ID] = newTeam.Drivers[index].ID
+			}
+			resp.Teams++
+			resp.Drivers += uint64(len(newTeam.Drivers))
+
+			stored := &current.Teams.Teams[len(current.Teams.Teams)-1]
+			if logo, ok := copyBundleAsset(assetDir, b, bundleTeam.Logo, fmt.Sprintf("team-%d-logo", stored.ID), resp); ok {
+				stored.Logo = logo
+				assetFiles = append(assetFiles, logo)
+			}
+			for index, d := range bundleTeam.Drivers {
+				if portrait, ok := copyBundleAsset(assetDir, b, d.Portrait, fmt.Sprintf("driver-%d-portrait", stored.Drivers[index].ID), resp); ok {
+					stored.Drivers[index].Portrait = portrait
+					assetFiles = append(assetFiles, portrait)
+				}
+			}
+		}
+
+		remapDrivers := func(ids []uint64) []uint64 {
+			remapped := make([]uint64, 0, len(ids))
+			for _, id := range ids {
+				remapped = append(remapped, driverIDs[id])
+			}
+			return remapped
+		}
+		remapClassEntries := func(entries []jsondb.ClassEntry) []jsondb.ClassEntry {
+			remapped := make([]jsondb.ClassEntry, 0, len(entries))
+			for _, entry := range entries {
+				remapped = append(remapped, jsondb.ClassEntry{DriverID: driverIDs[entry.DriverID], ClassID: entry.ClassID})
+			}
+			return remapped
+		}
+
+		season := &jsondb.Season{Name: options.SeasonName}
+		if source != nil {
+			// division moves point to seasons of the bundle and are not merged
+			season.DropRule = source.DropRule
+			season.Classes = source.Classes
+			season.ClassEntries = remapClassEntries(source.ClassEntries)
+			for _, division := range source.Divisions {
+				division.DriverIDs = remapDrivers(division.DriverIDs)
+				season.Divisions = append(season.Divisions, division)
+			}
+			if season.Name == "" {
+				season.Name = source.Name
+			}
+		}
+		if season.Name == "" {
+			season.Name = defaultMergedSeasonName
+		}
+		current.Seasons.Add(season)
+		resp.SeasonID = season.ID
+		resp.Seasons++
+
+		eventIDs := make(map[uint64]uint64)
+		for _, bundleEvent := range events {
+			newEvent := bundleEvent
+			newEvent.SeasonID = season.ID
+			newEvent.StartingGrid = remapPositions(bundleEvent.StartingGrid, teamIDs, driverIDs)
+			newEvent.Results = remapPositions(bundleEvent.Results, teamIDs, driverIDs)
+			newEvent.ClassEntries = remapClassEntries(bundleEvent.ClassEntries)
+			newEvent.Lineups = make([]jsondb.EventLineup, 0, len(bundleEvent.Lineups))
+			for _, lineup := range bundleEvent.Lineups {
+				newEvent.Lineups = append(newEvent.Lineups, jsondb.EventLineup{
+					TeamID:    teamIDs[lineup.TeamID],
+					DriverIDs: remapDrivers(lineup.DriverIDs),
+				})
+			}
+			if bundleEvent.GridGeneration != nil {
+				gridGeneration := *bundleEvent.GridGeneration
+				gridGeneration.StandingsEventIDs = make([]uint64, 0, len(bundleEvent.GridGeneration.StandingsEventIDs))
+				for _, eventID := range bundleEvent.GridGeneration.StandingsEventIDs {
+					if newID, ok := eventIDs[eventID]; ok {
+						gridGeneration.StandingsEventIDs = append(gridGeneration.StandingsEventIDs, newID)
+					}
+				}
+				newEvent.GridGeneration = &gridGeneration
+			}
+			if source == nil {
+				newEvent.DivisionID = 0
+			}
+
+			current.Events.Add(&newEvent)
+			eventIDs[bundleEvent.ID] = newEvent.ID
+			resp.Events++
+		}
+
+		for _, incident := range b.Snapshot.Incidents.Incidents {
+			newEventID, ok := eventIDs[incident.EventID]
+			if !ok {
+				continue
+			}
+
+			incident.EventID = newEventID
+			incident.DriverIDs = remapDrivers(incident.DriverIDs)
+			current.Incidents.Add(&incident)
+			resp.Incidents++
+		}
+
+		// reports are stored per event and the events are new, so none of them is replaced
+		for _, report := range b.Snapshot.Reports.Reports {
+			newEventID, ok := eventIDs[report.EventID]
+			if !ok {
+				continue
+			}
+
+			report.EventID = newEventID
+			current.Reports.Reports = append(current.Reports.Reports, report)
+			resp.Reports++
+		}
+
+		for _, alias := range b.Snapshot.Aliases.Aliases {
+			alias.DriverID = driverIDs[alias.DriverID]
+			if err := current.Aliases.Add(&alias); err != nil {
+				resp.Warnings = append(resp.Warnings, fmt.Sprintf("%s alias %s is already used", alias.Type.Name(), alias.Value))
+				continue
+			}
+			resp.Aliases++
+		}
+
+		return nil
+	})
+	if err != nil {
+		for _, fileName := range assetFiles {
+			if removeErr := os.Remove(filepath.Join(assetDir, fileName)); removeErr != nil {
+				logrus.WithError(removeErr).Warn("unable to remove merged asset")
+			}
+		}
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func remapPositions(positions []jsondb.RacePosition, teamIDs map[uint64]uint64, driverIDs map[uint64]uint64) []jsondb.RacePosition {
+	remapped := make([]jsondb.RacePosition, len(positions))
+	copy(remapped, positions)
+	for index := range remapped {
+		remapped[index].DriverID = driverIDs[remapped[index].DriverID]
+		remapped[index].TeamID = teamIDs[remapped[index].TeamID]
+	}
+
+	return remapped
+}
+
+// copyBundleAsset writes an image of the bundle under a new name in the same format as
+// uploaded images.
+func copyBundleAsset(assetDir string, b *bundle.Bundle, name string, prefix string, resp *bundleImportResponse) (string, bool) {
+	if name == "" {
+		return "", false
+	}
+
+	content, ok := b.Assets[filepath.Base(name)]
+	if !ok {
+		resp.Warnings = append(resp.Warnings, fmt.Sprintf("image %s is missing in the bundle", name))
+		return "", false
+	}
+
+	fileName := fmt.Sprintf("%s-%d%s", prefix, time.Now().UnixNano(), filepath.Ext(name))
+	if err := os.WriteFile(filepath.Join(assetDir, fileName), content, 0644); err != nil {
+		logrus.WithError(err).Warn("unable to write merged asset")
+		resp.Warnings = append(resp.Warnings, fmt.Sprintf("unable to copy image %s", name))
+		return "", false
+	}
+	resp.Assets++
+
+	return fileName, true
+}
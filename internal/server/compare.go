@@ -0,0 +1,86 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func GetCompareHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		teamNameMap, driverNameMap, err := buildNameMaps(repo)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to generate name maps")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		var nameMap map[uint64]string
+		var idParam string
+		var selectorFor func(id uint64) comparisonSelector
+		countPoints := func(pos jsondb.RacePosition) bool { return true }
+
+		if ctx.Query("drivers") != "" {
+			nameMap = driverNameMap
+			idParam = ctx.Query("drivers")
+			selectorFor = func(id uint64) comparisonSelector {
+				return func(pos jsondb.RacePosition) bool { return pos.DriverID == id }
+			}
+		} else if ctx.Query("teams") != "" {
+			settings, err := repo.GetSettings()
+			if err != nil {
+				logrus.WithError(err).Warn("unable to read settings")
+				ctx.AbortWithStatus(http.StatusInternalServerError)
+				return
+			}
+
+			nameMap = teamNameMap
+			idParam = ctx.Query("teams")
+			selectorFor = func(id uint64) comparisonSelector {
+				return func(pos jsondb.RacePosition) bool { return pos.TeamID == id }
+			}
+			countPoints = func(pos jsondb.RacePosition) bool {
+				return !pos.Substitute || settings.SubstitutePointsForTeam
+			}
+		} else {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		idParts := strings.Split(idParam, ",")
+		if len(idParts) != 2 {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		var entities [2]comparisonEntityResponse
+		var selectors [2]comparisonSelector
+		for index, idPart := range idParts {
+			id, err := strconv.Atoi(strings.TrimSpace(idPart))
+			if err != nil {
+				ctx.AbortWithStatus(http.StatusBadRequest)
+				return
+			}
+
+			name, ok := nameMap[uint64(id)]
+			if !ok {
+				ctx.AbortWithStatus(http.StatusNotFound)
+				return
+			}
+
+			entities[index] = comparisonEntityResponse{ID: uint64(id), Name: name}
+			selectors[index] = selectorFor(uint64(id))
+		}
+
+		events, ok := loadFilteredEvents(ctx, repo)
+		if !ok {
+			return
+		}
+
+		ctx.JSON(http.StatusOK, convertComparisonToResponse(entities, selectors, countPoints, events))
+	}
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	minTemperature = -60
+	maxTemperature = 100
+)
+
+var timeOfDayRegex = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]$`)
+
+func validateConditions(conditions *jsondb.SessionConditions) error {
+	for index, phase := range conditions.Weather {
+		if phase.Weather < jsondb.ClearWeather || phase.Weather > jsondb.StormWeather {
+			return fmt.Errorf("unknown weather %d", phase.Weather)
+		}
+		if index > 0 && phase.FromLap <= conditions.Weather[index-1].FromLap {
+			return fmt.Errorf("weather phases are not in lap order")
+		}
+	}
+
+	for _, temperature := range []*float64{conditions.AirTemperature, conditions.TrackTemperature} {
+		if temperature != nil && (*temperature < minTemperature || *temperature > maxTemperature) {
+			return fmt.Errorf("temperature %.1f out of range", *temperature)
+		}
+	}
+
+	if conditions.TrackState > jsondb.WetTrackState {
+		return fmt.Errorf("unknown track state %d", conditions.TrackState)
+	}
+
+	if conditions.TimeOfDay != "" && !timeOfDayRegex.MatchString(conditions.TimeOfDay) {
+		return fmt.Errorf("time of day %s is not in HH:MM format", conditions.TimeOfDay)
+	}
+
+	return nil
+}
+
+func hasTrackState(state jsondb.TrackState) func(e jsondb.RaceEvent) bool {
+	return func(e jsondb.RaceEvent) bool {
+		return e.Conditions != nil && e.Conditions.TrackState == state
+	}
+}
+
+func hasWeather(weather jsondb.Weather) func(e jsondb.RaceEvent) bool {
+	return func(e jsondb.RaceEvent) bool {
+		return e.Conditions != nil && e.Conditions.HasWeather(weather)
+	}
+}
+
+// filterEventsByConditions applies the optional track_state and weather query parameters.
+// The request is aborted if ok is false.
+func filterEventsByConditions(ctx *gin.Context, events []jsondb.RaceEvent) ([]jsondb.RaceEvent, bool) {
+	if stateParam := ctx.Query("track_state"); stateParam != "" {
+		state, err := strconv.Atoi(stateParam)
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return nil, false
+		}
+		events = filterEvents(events, hasTrackState(jsondb.TrackState(state)))
+	}
+
+	if weatherParam := ctx.Query("weather"); weatherParam != "" {
+		weather, err := strconv.Atoi(weatherParam)
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return nil, false
+		}
+		events = filterEvents(events, hasWeather(jsondb.Weather(weather)))
+	}
+
+	return events, true
+}
+
+func convertConditionsToResponse(conditions *jsondb.SessionConditions) *sessionConditionsResponse {
+	if conditions == nil {
+		return nil
+	}
+
+	weather := make([]weatherPhaseResponse, 0, len(conditions.Weather))
+	for _, phase := range conditions.Weather {
+		weather = append(weather, weatherPhaseResponse{FromLap: phase.FromLap, Weather: phase.Weather.Name()})
+	}
+
+	return &sessionConditionsResponse{
+		Weather:          weather,
+		AirTemperature:   conditions.AirTemperature,
+		TrackTemperature: conditions.TrackTemperature,
+		TrackState:       conditions.TrackState.Name(),
+		TimeOfDay:        conditions.TimeOfDay,
+	}
+}
+
+// convertConditionStats sums up the results of a driver in the events of one track state.
+func convertConditionStats(driverID uint64, events []jsondb.RaceEvent) conditionStatsResponse {
+	stats := conditionStatsResponse{}
+	var finishSum, finishCount uint64
+	for _, e := range events {
+		result, ok := findPosition(e.Results, driverID)
+		if !ok || result.Status == jsondb.DNSResultStatus {
+			continue
+		}
+
+		stats.Starts++
+		stats.Points += result.Points
+		switch result.Status {
+		case jsondb.DNFResultStatus:
+			stats.DNFs++
+		case jsondb.FinishedResultStatus:
+			finishSum += result.Position
+			finishCount++
+			if result.Position == 1 {
+				stats.Wins++
+			}
+			if result.Position <= 3 {
+				stats.Podiums++
+			}
+		}
+	}
+
+	if finishCount > 0 {
+		stats.AverageFinish = float64(finishSum) / float64(finishCount)
+	}
+
+	return stats
+}
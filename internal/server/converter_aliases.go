@@ -0,0 +1,18 @@
+package server
+
+import "github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+
+func convertAliasesToResponse(aliases []jsondb.Alias, driverNameMap map[uint64]string) []aliasResponse {
+	resp := make([]aliasResponse, 0, len(aliases))
+	for _, alias := range aliases {
+		resp = append(resp, aliasResponse{
+			ID:         alias.ID,
+			Type:       alias.Type.Name(),
+			Value:      alias.Value,
+			DriverID:   alias.DriverID,
+			DriverName: driverNameMap[alias.DriverID],
+		})
+	}
+
+	return resp
+}
@@ -0,0 +1,22 @@
+package server
+
+import "github.com/devnull-twitch/nyooom-backend/pkg/bundle"
+
+func convertRestoredBundleToResponse(b *bundle.Bundle, warnings []string) bundleImportResponse {
+	resp := bundleImportResponse{
+		Mode:      restoreBundleMode,
+		Teams:     uint64(len(b.Snapshot.Teams.Teams)),
+		Seasons:   uint64(len(b.Snapshot.Seasons.Seasons)),
+		Events:    uint64(len(b.Snapshot.Events.Events)),
+		Incidents: uint64(len(b.Snapshot.Incidents.Incidents)),
+		Reports:   uint64(len(b.Snapshot.Reports.Reports)),
+		Aliases:   uint64(len(b.Snapshot.Aliases.Aliases)),
+		Assets:    uint64(len(b.Assets)) - uint64(len(warnings)),
+		Warnings:  warnings,
+	}
+	for _, t := range b.Snapshot.Teams.Teams {
+		resp.Drivers += uint64(len(t.Drivers))
+	}
+
+	return resp
+}
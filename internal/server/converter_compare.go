@@ -0,0 +1,98 @@
+package server
+
+import "github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+
+// comparisonEntry is the performance of a driver or a team in a single event. For teams
+// the best position of all their drivers is used.
+type comparisonEntry struct {
+	Position uint64
+	Grid     uint64
+	Points   uint64
+}
+
+type comparisonSelector func(pos jsondb.RacePosition) bool
+
+func buildComparisonEntry(e jsondb.RaceEvent, selector comparisonSelector, countPoints func(pos jsondb.RacePosition) bool) (comparisonEntry, bool) {
+	entry := comparisonEntry{}
+	found := false
+	for _, res := range e.Results {
+		if !selector(res) {
+			continue
+		}
+
+		found = true
+		if entry.Position == 0 || res.Position < entry.Position {
+			entry.Position = res.Position
+		}
+		if countPoints(res) {
+			entry.Points += res.Points
+		}
+	}
+
+	for _, grid := range e.StartingGrid {
+		if selector(grid) && (entry.Grid == 0 || grid.Position < entry.Grid) {
+			entry.Grid = grid.Position
+		}
+	}
+
+	return entry, found
+}
+
+// convertComparisonToResponse compares two drivers or teams race by race over all events
+// in which both of them have a result.
+func convertComparisonToResponse(
+	entities [2]comparisonEntityResponse,
+	selectors [2]comparisonSelector,
+	countPoints func(pos jsondb.RacePosition) bool,
+	events []jsondb.RaceEvent,
+) comparisonResponse {
+	resp := comparisonResponse{
+		Entities: entities,
+		Events:   make([]comparisonEventResponse, 0),
+	}
+
+	var pointsDelta int64
+	for _, e := range sortEventsByDate(events) {
+		a, aOk := buildComparisonEntry(e, selectors[0], countPoints)
+		b, bOk := buildComparisonEntry(e, selectors[1], countPoints)
+		if !aOk || !bOk {
+			continue
+		}
+
+		pointsDelta += int64(a.Points) - int64(b.Points)
+		eventResp := comparisonEventResponse{
+			EventID:       e.ID,
+			EventName:     e.Name,
+			UnixDate:      e.Date,
+			Positions:     [2]uint64{a.Position, b.Position},
+			GridPositions: [2]uint64{a.Grid, b.Grid},
+			Points:        [2]uint64{a.Points, b.Points},
+			PointsDelta:   pointsDelta,
+		}
+
+		if winner := comparePositions(a.Position, b.Position); winner != 0 {
+			index := comparisonWinnerIndex(winner)
+			eventResp.FinishedAheadID = &resp.Entities[index].ID
+			resp.Tally.RaceWins[index]++
+		}
+		if winner := comparePositions(a.Grid, b.Grid); winner != 0 {
+			index := comparisonWinnerIndex(winner)
+			eventResp.QualifiedAheadID = &resp.Entities[index].ID
+			resp.Tally.QualiWins[index]++
+		}
+
+		resp.Tally.Points[0] += a.Points
+		resp.Tally.Points[1] += b.Points
+		resp.Events = append(resp.Events, eventResp)
+	}
+
+	return resp
+}
+
+func comparisonWinnerIndex(cmp int) int {
+	if cmp > 0 {
+		return 0
+	}
+
+	return 1
+}
@@ -0,0 +1,159 @@
+package server
+
+import "github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+
+func findPosition(positions []jsondb.RacePosition, driverID uint64) (jsondb.RacePosition, bool) {
+	for _, pos := range positions {
+		if pos.DriverID == driverID {
+			return pos, true
+		}
+	}
+
+	return jsondb.RacePosition{}, false
+}
+
+func convertDriverStatsToResponse(
+	driverID uint64,
+	events []jsondb.RaceEvent,
+	driverNameMap map[uint64]string,
+	branding brandingMaps,
+) driverStatsResponse {
+	resp := driverStatsResponse{
+		DriverID:               driverID,
+		DriverName:             driverNameMap[driverID],
+		driverBrandingResponse: branding.Drivers[driverID],
+		Teammates:              make([]teammateComparisonResponse, 0),
+	}
+
+	var finishSum, finishCount, gridSum, gridCount uint64
+	teammateIndex := make(map[uint64]int)
+	// results from newest to oldest are needed for the current streaks
+	driverResults := make([]jsondb.RacePosition, 0)
+
+	for _, e := range sortEventsByDate(events) {
+		// the order of a qualifying session is the grid, so it counts for poles and the average grid only
+		if isQualifyingEvent(e) {
+			if result, ok := findPosition(e.Results, driverID); ok && result.Status != jsondb.DNSResultStatus {
+				gridSum += result.Position
+				gridCount++
+				if result.Position == 1 {
+					resp.Poles++
+				}
+			}
+			continue
+		}
+
+		result, hasResult := findPosition(e.Results, driverID)
+		grid, hasGrid := findPosition(e.StartingGrid, driverID)
+
+		if hasGrid {
+			gridSum += grid.Position
+			gridCount++
+			if grid.Position == 1 {
+				resp.Poles++
+			}
+		}
+
+		if !hasResult || result.Status == jsondb.DNSResultStatus {
+			continue
+		}
+		driverResults = append(driverResults, result)
+
+		resp.Starts++
+		resp.Points += result.Points
+		if result.FastestLap {
+			resp.FastestLaps++
+		}
+
+		switch result.Status {
+		case jsondb.DNFResultStatus:
+			resp.DNFs++
+		case jsondb.DSQResultStatus:
+			resp.DSQs++
+		default:
+			finishSum += result.Position
+			finishCount++
+			if result.Position == 1 {
+				resp.Wins++
+			}
+			if result.Position <= 3 {
+				resp.Podiums++
+			}
+		}
+
+		if hasGrid {
+			resp.PositionsGained += int64(grid.Position) - int64(result.Position)
+		}
+
+		// teammates are the drivers that drove for the same team in this event
+		for _, other := range e.Results {
+			if other.DriverID == driverID || other.TeamID != result.TeamID {
+				continue
+			}
+
+			index, ok := teammateIndex[other.DriverID]
+			if !ok {
+				index = len(resp.Teammates)
+				teammateIndex[other.DriverID] = index
+				resp.Teammates = append(resp.Teammates, teammateComparisonResponse{
+					DriverID:   other.DriverID,
+					DriverName: driverNameMap[other.DriverID],
+				})
+			}
+
+			comparison := &resp.Teammates[index]
+			comparison.Events++
+			if result.Position < other.Position {
+				comparison.RaceAhead++
+			} else {
+				comparison.RaceBehind++
+			}
+
+			if otherGrid, ok := findPosition(e.StartingGrid, other.DriverID); ok && hasGrid {
+				if grid.Position < otherGrid.Position {
+					comparison.QualiAhead++
+				} else {
+					comparison.QualiBehind++
+				}
+			}
+		}
+	}
+
+	if finishCount > 0 {
+		resp.AverageFinish = float64(finishSum) / float64(finishCount)
+	}
+	if gridCount > 0 {
+		resp.AverageGrid = float64(gridSum) / float64(gridCount)
+	}
+
+	resp.CurrentStreaks = driverStreaksResponse{
+		Wins: currentStreak(driverResults, func(res jsondb.RacePosition) bool {
+			return res.Status == jsondb.FinishedResultStatus && res.Position == 1
+		}),
+		Podiums: currentStreak(driverResults, func(res jsondb.RacePosition) bool {
+			return res.Status == jsondb.FinishedResultStatus && res.Position <= 3
+		}),
+		Points: currentStreak(driverResults, func(res jsondb.RacePosition) bool {
+			return res.Points > 0
+		}),
+		Finishes: currentStreak(driverResults, func(res jsondb.RacePosition) bool {
+			return res.Status == jsondb.FinishedResultStatus
+		}),
+	}
+
+	return resp
+}
+
+// currentStreak counts how many of the most recent results in a row match the check.
+// Results have to be sorted from oldest to newest.
+func currentStreak(results []jsondb.RacePosition, check func(res jsondb.RacePosition) bool) uint64 {
+	var streak uint64
+	for index := len(results) - 1; index >= 0; index-- {
+		if !check(results[index]) {
+			break
+		}
+		streak++
+	}
+
+	return streak
+}
@@ -30,10 +30,11 @@ func convertEventsToResponse(
 	events []jsondb.RaceEvent,
 	teamNameMap map[uint64]string,
 	driverNameMap map[uint64]string,
+	branding brandingMaps,
 ) []eventResponse {
 	finalResp := make([]eventResponse, 0, len(events))
 	for _, event := range events {
-		finalResp = append(finalResp, convertEventToResponse(event, teamNameMap, driverNameMap))
+		finalResp = append(finalResp, convertEventToResponse(event, teamNameMap, driverNameMap, branding))
 	}
 
 	return finalResp
@@ -43,34 +44,91 @@ func convertEventToResponse(
 	event jsondb.RaceEvent,
 	teamNameMap map[uint64]string,
 	driverNameMap map[uint64]string,
+	branding brandingMaps,
 ) eventResponse {
 	grid := make([]eventGridResponse, 0)
 	for _, gridPos := range event.StartingGrid {
 		grid = append(grid, eventGridResponse{
-			DriverID:   gridPos.DriverID,
-			DriverName: driverNameMap[gridPos.DriverID],
-			TeamName:   teamNameMap[gridPos.TeamID],
-			Position:   gridPos.Position,
+			DriverID:       gridPos.DriverID,
+			DriverName:     driverNameMap[gridPos.DriverID],
+			DriverBranding: branding.Drivers[gridPos.DriverID],
+			TeamName:       teamNameMap[gridPos.TeamID],
+			TeamBranding:   branding.Teams[gridPos.TeamID],
+			Position:       gridPos.Position,
+			Substitute:     gridPos.Substitute,
+			ClassID:        gridPos.ClassID,
 		})
 	}
 
 	result := make([]eventResultResponse, 0)
 	for _, eventRes := range event.Results {
 		result = append(result, eventResultResponse{
-			DriverName: driverNameMap[eventRes.DriverID],
-			DriverID:   eventRes.DriverID,
-			TeamName:   teamNameMap[eventRes.TeamID],
-			Position:   eventRes.Position,
-			Points:     eventRes.Points,
+			DriverName:     driverNameMap[eventRes.DriverID],
+			DriverID:       eventRes.DriverID,
+			DriverBranding: branding.Drivers[eventRes.DriverID],
+			TeamName:       teamNameMap[eventRes.TeamID],
+			TeamBranding:   branding.Teams[eventRes.TeamID],
+			Position:       eventRes.Position,
+			Points:         eventRes.Points,
+			Substitute:     eventRes.Substitute,
+			Status:         eventRes.Status.Name(),
+			FastestLap:     eventRes.FastestLap,
+			BestLapMS:      eventRes.BestLapMS,
+			IncidentPoints: eventRes.IncidentPoints,
+
+			ClassID:       eventRes.ClassID,
+			ClassPosition: eventRes.ClassPosition,
+			ClassPoints:   eventRes.ClassPoints,
+
+			Laps:          eventRes.Laps,
+			StartCompound: convertStartCompound(eventRes),
+			PitStops:      convertPitStops(eventRes.PitStops),
 		})
 	}
 
+	lineups := make([]eventLineupResponse, 0, len(event.Lineups))
+	for _, lineup := range event.Lineups {
+		drivers := make([]eventLineupDriverResponse, 0, len(lineup.DriverIDs))
+		for _, driverID := range lineup.DriverIDs {
+			drivers = append(drivers, eventLineupDriverResponse{
+				DriverID:       driverID,
+				DriverName:     driverNameMap[driverID],
+				DriverBranding: branding.Drivers[driverID],
+			})
+		}
+
+		lineups = append(lineups, eventLineupResponse{
+			TeamID:       lineup.TeamID,
+			TeamName:     teamNameMap[lineup.TeamID],
+			TeamBranding: branding.Teams[lineup.TeamID],
+			Drivers:      drivers,
+		})
+	}
+
+	var gridGeneration *gridGenerationResponse
+	if event.GridGeneration != nil {
+		gridGeneration = &gridGenerationResponse{
+			Rule:              event.GridGeneration.Rule.Name(),
+			ReverseTopN:       event.GridGeneration.ReverseTopN,
+			Seed:              event.GridGeneration.Seed,
+			StandingsEventIDs: event.GridGeneration.StandingsEventIDs,
+			GeneratedAt:       event.GridGeneration.GeneratedAt,
+		}
+	}
+
 	return eventResponse{
 		ID:           event.ID,
 		Type:         event.Type.Name(),
+		Status:       event.Status.Name(),
+		SeasonID:     event.SeasonID,
 		UnixDate:     event.Date,
 		Name:         event.Name,
 		StartingGrid: grid,
 		Results:      result,
+		Lineups:      lineups,
+
+		GridGeneration: gridGeneration,
+		Conditions:     convertConditionsToResponse(event.Conditions),
+		ExternalID:     event.ExternalID,
 	}
 }
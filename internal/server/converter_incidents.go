@@ -0,0 +1,61 @@
+package server
+
+import "github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+
+func convertIncidentsToResponse(
+	incidents []jsondb.Incident,
+	events []jsondb.RaceEvent,
+	driverNameMap map[uint64]string,
+) []incidentResponse {
+	eventNameMap := make(map[uint64]string)
+	for _, e := range events {
+		eventNameMap[e.ID] = e.Name
+	}
+
+	resp := make([]incidentResponse, 0, len(incidents))
+	for _, i := range incidents {
+		drivers := make([]incidentDriverResponse, 0, len(i.DriverIDs))
+		for _, driverID := range i.DriverIDs {
+			drivers = append(drivers, incidentDriverResponse{
+				DriverID:   driverID,
+				DriverName: driverNameMap[driverID],
+			})
+		}
+
+		resp = append(resp, incidentResponse{
+			ID:               i.ID,
+			EventID:          i.EventID,
+			EventName:        eventNameMap[i.EventID],
+			Lap:              i.Lap,
+			Drivers:          drivers,
+			Description:      i.Description,
+			ReplayTimestamp:  i.ReplayTimestamp,
+			VODURL:           i.VODURL,
+			Outcome:          i.Outcome.Name(),
+			PenaltyReference: i.PenaltyReference,
+		})
+	}
+
+	return resp
+}
+
+func countIncidents(incidents []jsondb.Incident, driverID uint64) driverIncidentsResponse {
+	counts := driverIncidentsResponse{}
+	for _, i := range incidents {
+		if !IDisInList(i.DriverIDs, driverID) {
+			continue
+		}
+
+		counts.Total++
+		switch i.Outcome {
+		case jsondb.NoFurtherActionIncidentOutcome:
+			counts.NoFurtherAction++
+		case jsondb.WarningIncidentOutcome:
+			counts.Warnings++
+		case jsondb.PenaltyIncidentOutcome:
+			counts.Penalties++
+		}
+	}
+
+	return counts
+}
@@ -0,0 +1,41 @@
+package server
+
+func convertLiveSessionToResponse(
+	session liveSession,
+	live liveEvent,
+	teamNameMap map[uint64]string,
+	driverNameMap map[uint64]string,
+	branding brandingMaps,
+) liveSessionResponse {
+	entries := make([]liveEntryResponse, 0, len(session.Order)+len(session.Retired))
+	for index, driverID := range session.classification() {
+		assignment := live.Assignments[driverID]
+		entry := liveEntryResponse{
+			Position:       uint64(index + 1),
+			DriverID:       driverID,
+			DriverName:     driverNameMap[driverID],
+			DriverBranding: branding.Drivers[driverID],
+			TeamID:         assignment.TeamID,
+			TeamName:       teamNameMap[assignment.TeamID],
+			TeamBranding:   branding.Teams[assignment.TeamID],
+			Retired:        IDisInList(session.Retired, driverID),
+			FastestLap:     session.FastestLap != nil && *session.FastestLap == driverID,
+		}
+
+		if grid, ok := findPosition(live.Event.StartingGrid, driverID); ok {
+			entry.PositionsGained = int64(grid.Position) - int64(entry.Position)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return liveSessionResponse{
+		EventID:   live.Event.ID,
+		EventName: live.Event.Name,
+		Lap:       session.Lap,
+		TotalLaps: session.TotalLaps,
+		StartedAt: session.StartedAt,
+		UpdatedAt: session.UpdatedAt,
+		Entries:   entries,
+	}
+}
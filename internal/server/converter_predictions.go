@@ -0,0 +1,142 @@
+package server
+
+import (
+	"sort"
+
+	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+)
+
+type predictionScore struct {
+	Podium     uint64
+	Pole       uint64
+	FastestLap uint64
+}
+
+func (s predictionScore) Total() uint64 {
+	return s.Podium + s.Pole + s.FastestLap
+}
+
+// scorePrediction returns false as long as the event has no results to score against.
+func scorePrediction(p jsondb.Prediction, event jsondb.RaceEvent, scoring jsondb.PredictionScoring) (predictionScore, bool) {
+	score := predictionScore{}
+	if !isCompletedEvent(event) || len(event.Results) <= 0 {
+		return score, false
+	}
+
+	// maps the drivers on the podium to their position
+	podium := make(map[uint64]uint64)
+	for _, result := range event.Results {
+		if result.Position >= 1 && result.Position <= predictionPodiumSize {
+			podium[result.DriverID] = result.Position
+		}
+	}
+
+	for index, driverID := range p.Podium {
+		position, onPodium := podium[driverID]
+		if !onPodium {
+			continue
+		}
+
+		if position == uint64(index+1) {
+			score.Podium += scoring.PodiumExact
+		} else {
+			score.Podium += scoring.PodiumDriver
+		}
+	}
+
+	if p.PoleDriverID != nil {
+		for _, grid := range event.StartingGrid {
+			if grid.Position == 1 && grid.DriverID == *p.PoleDriverID {
+				score.Pole = scoring.Pole
+			}
+		}
+	}
+
+	if p.FastestLapDriverID != nil {
+		for _, result := range event.Results {
+			if result.FastestLap && result.DriverID == *p.FastestLapDriverID {
+				score.FastestLap = scoring.FastestLap
+			}
+		}
+	}
+
+	return score, true
+}
+
+// convertPredictionToResponse only adds the score if a scoring scheme is given and the event has results.
+func convertPredictionToResponse(p jsondb.Prediction, event jsondb.RaceEvent, scoring *jsondb.PredictionScoring) predictionResponse {
+	resp := predictionResponse{
+		ID:                 p.ID,
+		EventID:            p.EventID,
+		Viewer:             p.Viewer,
+		Podium:             p.Podium,
+		PoleDriverID:       p.PoleDriverID,
+		FastestLapDriverID: p.FastestLapDriverID,
+		SubmittedAt:        p.SubmittedAt,
+	}
+	if scoring == nil {
+		return resp
+	}
+
+	if score, ok := scorePrediction(p, event, *scoring); ok {
+		resp.Score = &predictionScoreResponse{
+			Podium:     score.Podium,
+			Pole:       score.Pole,
+			FastestLap: score.FastestLap,
+			Total:      score.Total(),
+		}
+	}
+
+	return resp
+}
+
+func convertPredictionLeaderboardToResponse(
+	predictions []jsondb.Prediction,
+	events []jsondb.RaceEvent,
+	scoring jsondb.PredictionScoring,
+) []predictionLeaderboardRowResponse {
+	eventMap := make(map[uint64]jsondb.RaceEvent)
+	for _, e := range events {
+		eventMap[e.ID] = e
+	}
+
+	rows := make([]predictionLeaderboardRowResponse, 0)
+	rowIndex := make(map[string]int)
+	for _, p := range predictions {
+		e, ok := eventMap[p.EventID]
+		if !ok {
+			continue
+		}
+
+		score, scored := scorePrediction(p, e, scoring)
+		if !scored {
+			continue
+		}
+
+		index, ok := rowIndex[p.Viewer]
+		if !ok {
+			index = len(rows)
+			rowIndex[p.Viewer] = index
+			rows = append(rows, predictionLeaderboardRowResponse{Viewer: p.Viewer})
+		}
+
+		rows[index].Points += score.Total()
+		rows[index].Predictions++
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].Points != rows[j].Points {
+			return rows[i].Points > rows[j].Points
+		}
+		return rows[i].Viewer < rows[j].Viewer
+	})
+
+	for index := range rows {
+		rows[index].Position = uint64(index + 1)
+		if index > 0 && rows[index-1].Points == rows[index].Points {
+			rows[index].Position = rows[index-1].Position
+		}
+	}
+
+	return rows
+}
@@ -0,0 +1,190 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+)
+
+// maxEventPoints is the most points an entry with the given number of cars can score in
+// a single event of that type.
+func maxEventPoints(eventType jsondb.EventType, cars int) uint64 {
+	var points uint64
+	for index := 0; index < cars; index++ {
+		points += getPointsByIndex(eventType, index)
+	}
+
+	return points
+}
+
+func maxRoundPoints(round []jsondb.RaceEvent, cars int) uint64 {
+	var points uint64
+	for _, e := range round {
+		points += maxEventPoints(e.Type, cars)
+	}
+
+	return points
+}
+
+// convertScenariosToResponse calculates which drivers and teams can still win the title
+// with the remaining events. Potential points ignore dropped scores and are an upper bound.
+func convertScenariosToResponse(
+	table standings,
+	teams []jsondb.Team,
+	remaining []jsondb.RaceEvent,
+	config standingsConfig,
+) scenariosResponse {
+	rounds := groupEventsIntoRounds(remaining)
+
+	teamCars := make(map[uint64]int)
+	for _, t := range teams {
+		cars := 0
+		for _, d := range t.Drivers {
+			if d.Role == jsondb.RaceDriverRole {
+				cars++
+			}
+		}
+		if cars <= 0 {
+			cars = 1
+		}
+		teamCars[t.ID] = cars
+	}
+
+	remainingEvents := make([]progressionEventResponse, 0, len(remaining))
+	for _, round := range rounds {
+		for _, e := range round {
+			remainingEvents = append(remainingEvents, progressionEventResponse{ID: e.ID, Name: e.Name, Type: e.Type.Name()})
+		}
+	}
+
+	return scenariosResponse{
+		RemainingEvents: remainingEvents,
+		Drivers:         buildScenarioRows(table.Drivers, rounds, func(id uint64) int { return 1 }),
+		Teams:           buildScenarioRows(table.Teams, rounds, func(id uint64) int { return teamCars[id] }),
+	}
+}
+
+func buildScenarioRows(rows []*standingsRow, rounds [][]jsondb.RaceEvent, cars func(id uint64) int) []scenarioRowResponse {
+	resp := make([]scenarioRowResponse, 0, len(rows))
+	if len(rows) <= 0 {
+		return resp
+	}
+
+	totalMax := make(map[uint64]uint64)
+	nextMax := make(map[uint64]uint64)
+	for _, row := range rows {
+		for index, round := range rounds {
+			roundMax := maxRoundPoints(round, cars(row.ID))
+			totalMax[row.ID] += roundMax
+			if index == 0 {
+				nextMax[row.ID] = roundMax
+			}
+		}
+	}
+
+	leader := rows[0]
+	for _, row := range rows {
+		rowResp := scenarioRowResponse{
+			Position:  row.Position,
+			ID:        row.ID,
+			Name:      row.Name,
+			Points:    row.Points,
+			MaxPoints: row.Points + totalMax[row.ID],
+		}
+
+		// ties can not be resolved before the tie breakers are known so only a real lead clinches
+		rowResp.Clinched = row == leader
+		for _, other := range rows {
+			if other != row && other.Points+totalMax[other.ID] >= row.Points {
+				rowResp.Clinched = false
+				break
+			}
+		}
+		rowResp.Eliminated = rowResp.MaxPoints < leader.Points
+
+		if !rowResp.Clinched && !rowResp.Eliminated && len(rounds) > 0 {
+			rowResp.NextRound = buildNextRoundScenario(row, leader, rows, totalMax, nextMax)
+		}
+
+		rowResp.Summary = summarizeScenario(rowResp, row == leader)
+		resp = append(resp, rowResp)
+	}
+
+	return resp
+}
+
+// buildNextRoundScenario calculates by how many points the entry has to outscore its rivals
+// in the next round to clinch the title or to avoid elimination against the current leader.
+func buildNextRoundScenario(
+	row *standingsRow,
+	leader *standingsRow,
+	rows []*standingsRow,
+	totalMax map[uint64]uint64,
+	nextMax map[uint64]uint64,
+) *scenarioNextRoundResponse {
+	afterNext := func(id uint64) int64 {
+		return int64(totalMax[id]) - int64(nextMax[id])
+	}
+
+	var clinchMargin int64
+	first := true
+	for _, other := range rows {
+		if other == row {
+			continue
+		}
+
+		margin := int64(other.Points) + afterNext(other.ID) - int64(row.Points) + 1
+		if first || margin > clinchMargin {
+			clinchMargin = margin
+			first = false
+		}
+	}
+
+	next := &scenarioNextRoundResponse{
+		MaxPoints:    nextMax[row.ID],
+		CanClinch:    clinchMargin <= int64(nextMax[row.ID]),
+		ClinchMargin: clinchMargin,
+	}
+
+	if row != leader {
+		survivalMargin := int64(leader.Points) - int64(row.Points) - afterNext(row.ID)
+		next.SurvivalMargin = &survivalMargin
+	}
+
+	return next
+}
+
+func summarizeScenario(row scenarioRowResponse, isLeader bool) string {
+	if row.Clinched {
+		return "Has clinched the title"
+	}
+	if row.Eliminated {
+		return "Is mathematically eliminated"
+	}
+	if row.NextRound == nil {
+		if isLeader {
+			return "Leads the championship"
+		}
+		return "Is still in contention"
+	}
+
+	if row.NextRound.SurvivalMargin != nil && !row.NextRound.CanClinch {
+		margin := *row.NextRound.SurvivalMargin
+		if margin > 0 {
+			return fmt.Sprintf("Must outscore the leader by at least %d points in the next round to stay in contention", margin)
+		}
+		if -margin >= int64(row.NextRound.MaxPoints) {
+			return "Stays in contention after the next round"
+		}
+		return fmt.Sprintf("Stays in contention unless the leader outscores them by more than %d points in the next round", -margin)
+	}
+
+	if row.NextRound.CanClinch {
+		if row.NextRound.ClinchMargin > 0 {
+			return fmt.Sprintf("Clinches the title in the next round by outscoring every rival by at least %d points", row.NextRound.ClinchMargin)
+		}
+		return fmt.Sprintf("Clinches the title in the next round unless a rival outscores them by %d points or more", -row.NextRound.ClinchMargin+1)
+	}
+
+	return "Can not clinch the title in the next round"
+}
@@ -0,0 +1,69 @@
+package server
+
+func convertSimulationToResponse(
+	baseline standings,
+	tally simulationTally,
+	templates []simulationTemplate,
+	runs int,
+	seed int64,
+) simulationResponse {
+	remainingEvents := make([]progressionEventResponse, 0, len(templates))
+	for _, template := range templates {
+		remainingEvents = append(remainingEvents, progressionEventResponse{
+			ID:   template.Event.ID,
+			Name: template.Event.Name,
+			Type: template.Event.Type.Name(),
+		})
+	}
+
+	return simulationResponse{
+		Runs:            runs,
+		Seed:            seed,
+		RemainingEvents: remainingEvents,
+		Drivers:         convertSimulationEntries(tally.Drivers, baseline.Drivers, runs),
+		Teams:           convertSimulationEntries(tally.Teams, baseline.Teams, runs),
+	}
+}
+
+// convertSimulationEntries returns probabilities of equal length for all entries so clients can
+// render them as a table.
+func convertSimulationEntries(entries []*simulationEntry, rows []*standingsRow, runs int) []simulationRowResponse {
+	current := make(map[uint64]*standingsRow)
+	for _, row := range rows {
+		current[row.ID] = row
+	}
+
+	positions := 0
+	for _, entry := range entries {
+		if len(entry.Positions) > positions {
+			positions = len(entry.Positions)
+		}
+	}
+
+	resp := make([]simulationRowResponse, 0, len(entries))
+	for _, entry := range entries {
+		rowResp := simulationRowResponse{
+			ID:                    entry.ID,
+			Name:                  entry.Name,
+			PositionProbabilities: make([]float64, positions),
+		}
+		if row, ok := current[entry.ID]; ok {
+			rowResp.Position = row.Position
+			rowResp.Points = row.Points
+		}
+
+		var counted, positionSum uint64
+		for index, count := range entry.Positions {
+			rowResp.PositionProbabilities[index] = float64(count) / float64(runs)
+			counted += count
+			positionSum += count * uint64(index+1)
+		}
+		if counted > 0 {
+			rowResp.ExpectedPosition = float64(positionSum) / float64(counted)
+		}
+
+		resp = append(resp, rowResp)
+	}
+
+	return resp
+}
@@ -0,0 +1,103 @@
+package server
+
+import "github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+
+func convertStandingsToResponse(table standings, events []jsondb.RaceEvent, teamNameMap map[uint64]string) standingsResponse {
+	eventNameMap := make(map[uint64]string)
+	for _, e := range events {
+		eventNameMap[e.ID] = e.Name
+	}
+
+	resp := standingsResponse{
+		Drivers: make([]driverStandingsRowResponse, 0, len(table.Drivers)),
+		Teams:   make([]standingsRowResponse, 0, len(table.Teams)),
+	}
+
+	for _, row := range table.Drivers {
+		resp.Drivers = append(resp.Drivers, driverStandingsRowResponse{
+			standingsRowResponse: convertStandingsRowToResponse(row, eventNameMap),
+			TeamID:               row.TeamID,
+			TeamName:             teamNameMap[row.TeamID],
+		})
+	}
+
+	for _, row := range table.Teams {
+		resp.Teams = append(resp.Teams, convertStandingsRowToResponse(row, eventNameMap))
+	}
+
+	return resp
+}
+
+func convertStandingsRowToResponse(row *standingsRow, eventNameMap map[uint64]string) standingsRowResponse {
+	results := make([]standingsResultResponse, 0, len(row.Results))
+	for _, res := range row.Results {
+		results = append(results, standingsResultResponse{
+			EventID:   res.EventID,
+			EventName: eventNameMap[res.EventID],
+			Position:  res.Position,
+			Points:    res.Points,
+			Dropped:   res.Dropped,
+		})
+	}
+
+	return standingsRowResponse{
+		Position:    row.Position,
+		ID:          row.ID,
+		Name:        row.Name,
+		Points:      row.Points,
+		GrossPoints: row.GrossPoints,
+		Wins:        countFinishes(row.Results, 1),
+		DecidedBy:   row.DecidedBy,
+		Results:     results,
+	}
+}
+
+// convertProgressionToResponse calculates the standings after every round. Position changes
+// are relative to the previous round, entries without a previous position have no change.
+func convertProgressionToResponse(teams []jsondb.Team, rounds [][]jsondb.RaceEvent, config standingsConfig) []progressionRoundResponse {
+	resp := make([]progressionRoundResponse, 0, len(rounds))
+	prevDriverPositions := make(map[uint64]uint64)
+	prevTeamPositions := make(map[uint64]uint64)
+	eventsSoFar := make([]jsondb.RaceEvent, 0)
+
+	for roundIndex, round := range rounds {
+		eventsSoFar = append(eventsSoFar, round...)
+		table := buildStandings(teams, eventsSoFar, config)
+
+		roundEvents := make([]progressionEventResponse, 0, len(round))
+		for _, e := range round {
+			roundEvents = append(roundEvents, progressionEventResponse{ID: e.ID, Name: e.Name, Type: e.Type.Name()})
+		}
+
+		resp = append(resp, progressionRoundResponse{
+			Round:    uint64(roundIndex + 1),
+			UnixDate: round[0].Date,
+			Events:   roundEvents,
+			Drivers:  convertProgressionRows(table.Drivers, prevDriverPositions),
+			Teams:    convertProgressionRows(table.Teams, prevTeamPositions),
+		})
+	}
+
+	return resp
+}
+
+func convertProgressionRows(rows []*standingsRow, prevPositions map[uint64]uint64) []progressionRowResponse {
+	resp := make([]progressionRowResponse, 0, len(rows))
+	for _, row := range rows {
+		var change int64
+		if prev, ok := prevPositions[row.ID]; ok {
+			change = int64(prev) - int64(row.Position)
+		}
+		prevPositions[row.ID] = row.Position
+
+		resp = append(resp, progressionRowResponse{
+			ID:             row.ID,
+			Name:           row.Name,
+			Position:       row.Position,
+			Points:         row.Points,
+			PositionChange: change,
+		})
+	}
+
+	return resp
+}
@@ -0,0 +1,161 @@
+package server
+
+import "github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+
+// convertStartCompound is empty for results without any strategy data.
+func convertStartCompound(res jsondb.RacePosition) string {
+	if res.StartCompound == jsondb.UnknownTyreCompound {
+		return ""
+	}
+
+	return res.StartCompound.Name()
+}
+
+func convertPitStops(stops []jsondb.PitStop) []pitStopResponse {
+	if len(stops) <= 0 {
+		return nil
+	}
+
+	resp := make([]pitStopResponse, 0, len(stops))
+	for _, stop := range stops {
+		resp = append(resp, pitStopResponse{
+			Lap:              stop.Lap,
+			StationaryTimeMS: stop.StationaryTimeMS,
+			ServiceTimeMS:    stop.ServiceTimeMS(),
+			Compound:         stop.Compound.Name(),
+			PenaltyServedMS:  stop.PenaltyServedMS,
+		})
+	}
+
+	return resp
+}
+
+// buildStints splits the race of a driver at the pit stops. A stop without a compound keeps the
+// tyres of the previous stint. The last stint is open ended if the laps of the driver are unknown.
+func buildStints(res jsondb.RacePosition) []stintResponse {
+	stints := make([]stintResponse, 0, len(res.PitStops)+1)
+	if res.StartCompound == jsondb.UnknownTyreCompound && len(res.PitStops) <= 0 && res.Laps == 0 {
+		return stints
+	}
+
+	current := stintResponse{Stint: 1, Compound: res.StartCompound.Name(), StartLap: 1}
+	compound := res.StartCompound
+
+	for _, stop := range res.PitStops {
+		current.EndLap = stop.Lap
+		current.Laps = stop.Lap - current.StartLap + 1
+		stints = append(stints, current)
+
+		if stop.Compound != jsondb.UnknownTyreCompound {
+			compound = stop.Compound
+		}
+		current = stintResponse{Stint: current.Stint + 1, Compound: compound.Name(), StartLap: stop.Lap + 1}
+	}
+
+	if res.Laps >= current.StartLap {
+		current.EndLap = res.Laps
+		current.Laps = res.Laps - current.StartLap + 1
+	}
+	// a stop on the last lap does not start another stint
+	if res.Laps == 0 || res.Laps >= current.StartLap {
+		stints = append(stints, current)
+	}
+
+	return stints
+}
+
+func convertEventStrategyToResponse(
+	event jsondb.RaceEvent,
+	teams []jsondb.Team,
+	teamNameMap map[uint64]string,
+	driverNameMap map[uint64]string,
+) eventStrategyResponse {
+	resp := eventStrategyResponse{
+		EventID:   event.ID,
+		EventName: event.Name,
+		Drivers:   make([]driverStrategyResponse, 0, len(event.Results)),
+	}
+
+	for _, res := range event.Results {
+		pitStops := convertPitStops(res.PitStops)
+		if pitStops == nil {
+			pitStops = make([]pitStopResponse, 0)
+		}
+
+		resp.Drivers = append(resp.Drivers, driverStrategyResponse{
+			DriverID:   res.DriverID,
+			DriverName: driverNameMap[res.DriverID],
+			TeamID:     res.TeamID,
+			TeamName:   teamNameMap[res.TeamID],
+			Position:   res.Position,
+			Status:     res.Status.Name(),
+			Laps:       res.Laps,
+			Stops:      uint64(len(res.PitStops)),
+			Stints:     buildStints(res),
+			PitStops:   pitStops,
+		})
+	}
+
+	resp.Teams = convertPitStopStatsToResponse([]jsondb.RaceEvent{event}, teams, driverNameMap)
+	return resp
+}
+
+// convertPitStopStatsToResponse aggregates the pit stops of every team. Fastest and average stop
+// use the service time without served penalties and ignore stops without a time.
+func convertPitStopStatsToResponse(
+	events []jsondb.RaceEvent,
+	teams []jsondb.Team,
+	driverNameMap map[uint64]string,
+) []teamPitStopStatsResponse {
+	eventNameMap := make(map[uint64]string)
+	for _, e := range events {
+		eventNameMap[e.ID] = e.Name
+	}
+
+	stats := make(map[uint64]*teamPitStopStatsResponse)
+	timedStops := make(map[uint64]uint64)
+	timeSums := make(map[uint64]uint64)
+	for _, e := range sortEventsByDate(events) {
+		for _, res := range e.Results {
+			for _, stop := range res.PitStops {
+				teamStats, ok := stats[res.TeamID]
+				if !ok {
+					teamStats = &teamPitStopStatsResponse{TeamID: res.TeamID}
+					stats[res.TeamID] = teamStats
+				}
+				teamStats.Stops++
+
+				serviceTime := stop.ServiceTimeMS()
+				if serviceTime == 0 {
+					continue
+				}
+				timedStops[res.TeamID]++
+				timeSums[res.TeamID] += serviceTime
+
+				if teamStats.FastestStopMS == 0 || serviceTime < teamStats.FastestStopMS {
+					teamStats.FastestStopMS = serviceTime
+					teamStats.FastestStopDriverID = res.DriverID
+					teamStats.FastestStopDriverName = driverNameMap[res.DriverID]
+					teamStats.FastestStopEventID = e.ID
+					teamStats.FastestStopEventName = eventNameMap[e.ID]
+				}
+			}
+		}
+	}
+
+	resp := make([]teamPitStopStatsResponse, 0, len(stats))
+	for _, t := range teams {
+		teamStats, ok := stats[t.ID]
+		if !ok {
+			continue
+		}
+
+		teamStats.TeamName = t.Name
+		if timedStops[t.ID] > 0 {
+			teamStats.AverageStopMS = float64(timeSums[t.ID]) / float64(timedStops[t.ID])
+		}
+		resp = append(resp, *teamStats)
+	}
+
+	return resp
+}
@@ -6,27 +6,30 @@ import (
 	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
 )
 
-func convertTeamsToResponse(teams []jsondb.Team, events []jsondb.RaceEvent) []teamResponse {
+func convertTeamsToResponse(teams []jsondb.Team, events []jsondb.RaceEvent, settings jsondb.Settings) []teamResponse {
 	teamMap := make(map[uint64]*teamResponse)
 	driverMap := make(map[uint64]*driverResponse)
 	teamDriverIDs := make(map[uint64][]uint64)
 
 	for _, t := range teams {
 		teamMap[t.ID] = &teamResponse{
-			ID:      t.ID,
-			Name:    t.Name,
-			Results: make([]teamResultResponse, 0),
-			Drivers: make([]driverResponse, 2),
+			ID:                   t.ID,
+			Name:                 t.Name,
+			teamBrandingResponse: convertTeamBranding(t),
+			Results:              make([]teamResultResponse, 0),
+			Drivers:              make([]driverResponse, 0, len(t.Drivers)),
 		}
 
 		driverIDs := make([]uint64, 0)
 
 		for _, d := range t.Drivers {
 			driverMap[d.ID] = &driverResponse{
-				ID:      d.ID,
-				Name:    d.Name,
-				Points:  0,
-				Results: make([]driverResultResponse, 0),
+				ID:                     d.ID,
+				Name:                   d.Name,
+				Role:                   d.Role.Name(),
+				driverBrandingResponse: convertDriverBranding(d),
+				Points:                 0,
+				Results:                make([]driverResultResponse, 0),
 			}
 
 			driverIDs = append(driverIDs, d.ID)
@@ -44,44 +47,66 @@ func convertTeamsToResponse(teams []jsondb.Team, events []jsondb.RaceEvent) []te
 				prevPoints = result.Points
 			}
 
+			driver, driverExists := driverMap[result.DriverID]
+			if team, ok := teamMap[result.TeamID]; ok {
+				// substitutes always score for themselves but only score for the team if configured
+				teamPoints, teamPrevPoints := result.Points, prevPoints
+				if result.Substitute && !settings.SubstitutePointsForTeam {
+					teamPoints, teamPrevPoints = 0, 0
+				}
+
+				if e.Type == jsondb.RaceEventType || e.Type == jsondb.SprintEventType {
+					team.Points += teamPoints
+					team.PrevPoints += teamPrevPoints
+				} else {
+					team.PreSeasonPoints += teamPoints
+					team.PrevPreSeasonPoints += teamPrevPoints
+				}
+
+				driverName := ""
+				if driverExists {
+					driverName = driver.Name
+				}
+				team.Results = append(team.Results, teamResultResponse{
+					EventName:  e.Name,
+					DriverName: driverName,
+					Points:     teamPoints,
+					Position:   result.Position,
+					Substitute: result.Substitute,
+				})
+			}
+
+			if !driverExists {
+				continue
+			}
 			if e.Type == jsondb.RaceEventType || e.Type == jsondb.SprintEventType {
-				teamMap[result.TeamID].Points += result.Points
-				teamMap[result.TeamID].PrevPoints += prevPoints
+				driver.Points += result.Points
+				driver.PrevPoints += prevPoints
 			} else {
-				teamMap[result.TeamID].PreSeasonPoints += result.Points
-				teamMap[result.TeamID].PrevPreSeasonPoints += prevPoints
+				driver.PreSeasonPoints += result.Points
+				driver.PrevPreSeasonPoints += prevPoints
 			}
-			teamMap[result.TeamID].Results = append(teamMap[result.TeamID].Results, teamResultResponse{
+			driver.Results = append(driver.Results, driverResultResponse{
 				EventName:  e.Name,
-				DriverName: driverMap[result.DriverID].Name,
 				Points:     result.Points,
 				Position:   result.Position,
-			})
-			if e.Type == jsondb.RaceEventType || e.Type == jsondb.SprintEventType {
-				driverMap[result.DriverID].Points += result.Points
-				driverMap[result.DriverID].PrevPoints += prevPoints
-			} else {
-				driverMap[result.DriverID].PreSeasonPoints += result.Points
-				driverMap[result.DriverID].PrevPreSeasonPoints += prevPoints
-			}
-			driverMap[result.DriverID].Results = append(driverMap[result.DriverID].Results, driverResultResponse{
-				EventName: e.Name,
-				Points:    result.Points,
-				Position:  result.Position,
+				Substitute: result.Substitute,
 			})
 		}
 	}
 
-	finalArray := make([]teamResponse, len(teamMap))
-	index := 0
-	for teamID, teamPtr := range teamMap {
-		finalArray[index] = *teamPtr
+	teamStandings := buildStandings(teams, filterEvents(events, isChampionshipEvent), standingsConfig{Settings: settings})
+
+	finalArray := make([]teamResponse, 0, len(teamStandings.Teams))
+	for _, row := range teamStandings.Teams {
+		teamResp := *teamMap[row.ID]
+		teamResp.Position = row.Position
 
-		for driverIndex, id := range teamDriverIDs[teamID] {
-			finalArray[index].Drivers[driverIndex] = *driverMap[id]
+		for _, id := range teamDriverIDs[row.ID] {
+			teamResp.Drivers = append(teamResp.Drivers, *driverMap[id])
 		}
 
-		index++
+		finalArray = append(finalArray, teamResp)
 	}
 
 	return finalArray
@@ -90,14 +115,15 @@ func convertTeamsToResponse(teams []jsondb.Team, events []jsondb.RaceEvent) []te
 func convertTeamFlat(team jsondb.Team) teamResponse {
 	driverList := make([]driverResponse, len(team.Drivers))
 	for i, d := range team.Drivers {
-		driverList[i] = driverResponse{ID: d.ID, Name: d.Name}
+		driverList[i] = driverResponse{ID: d.ID, Name: d.Name, Role: d.Role.Name(), driverBrandingResponse: convertDriverBranding(d)}
 	}
 
 	return teamResponse{
-		ID:      team.ID,
-		Name:    team.Name,
-		Results: []teamResultResponse{},
-		Drivers: driverList,
+		ID:                   team.ID,
+		Name:                 team.Name,
+		teamBrandingResponse: convertTeamBranding(team),
+		Results:              []teamResultResponse{},
+		Drivers:              driverList,
 	}
 }
 
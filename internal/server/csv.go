@@ -0,0 +1,176 @@
+package server
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const csvFormat = "csv"
+
+func wantsCSV(ctx *gin.Context) bool {
+	return ctx.Query("format") == csvFormat
+}
+
+// respondCSV sends the rows as a CSV download. The first row is the header.
+func respondCSV(ctx *gin.Context, fileName string, rows [][]string) {
+	buf := &bytes.Buffer{}
+	writer := csv.NewWriter(buf)
+	if err := writer.WriteAll(rows); err != nil {
+		logrus.WithError(err).Warn("unable to write csv")
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	ctx.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// csvText keeps spreadsheets from evaluating user input as a formula.
+func csvText(value string) string {
+	if value != "" && strings.ContainsAny(value[:1], "=+-@\t\r") {
+		return "'" + value
+	}
+
+	return value
+}
+
+func csvUint(value uint64) string {
+	return strconv.FormatUint(value, 10)
+}
+
+func csvBool(value bool) string {
+	if value {
+		return "yes"
+	}
+
+	return ""
+}
+
+func convertTeamsToCSV(teams []teamResponse) [][]string {
+	rows := [][]string{{
+		"team_id", "team_name", "team_points",
+		"driver_id", "driver_name", "role", "abbreviation", "nationality", "car_number", "driver_points",
+	}}
+
+	for _, t := range teams {
+		teamColumns := []string{csvUint(t.ID), csvText(t.Name), csvUint(t.Points)}
+		if len(t.Drivers) <= 0 {
+			rows = append(rows, append(teamColumns, "", "", "", "", "", "", ""))
+			continue
+		}
+
+		for _, d := range t.Drivers {
+			carNumber := ""
+			if d.CarNumber > 0 {
+				carNumber = csvUint(d.CarNumber)
+			}
+
+			rows = append(rows, append(append([]string{}, teamColumns...),
+				csvUint(d.ID),
+				csvText(d.Name),
+				d.Role,
+				csvText(d.Abbreviation),
+				csvText(d.Nationality),
+				carNumber,
+				csvUint(d.Points),
+			))
+		}
+	}
+
+	return rows
+}
+
+// convertEventsToCSV writes one row per result. Events without results get a single row
+// with empty result columns.
+func convertEventsToCSV(events []eventResponse) [][]string {
+	rows := [][]string{{
+		"event_id", "event_name", "date", "type", "event_status", "season_id",
+		"position", "driver_id", "driver_name", "team_name", "points", "status", "fastest_lap", "best_lap", "laps",
+	}}
+
+	for _, e := range events {
+		eventColumns := []string{
+			csvUint(e.ID),
+			csvText(e.Name),
+			time.Unix(e.UnixDate, 0).UTC().Format(time.RFC3339),
+			e.Type,
+			e.Status,
+			csvUint(e.SeasonID),
+		}
+		if len(e.Results) <= 0 {
+			rows = append(rows, append(eventColumns, "", "", "", "", "", "", "", "", ""))
+			continue
+		}
+
+		for _, res := range e.Results {
+			bestLap := ""
+			if res.BestLapMS > 0 {
+				bestLap = formatLapTime(res.BestLapMS)
+			}
+			laps := ""
+			if res.Laps > 0 {
+				laps = csvUint(res.Laps)
+			}
+
+			rows = append(rows, append(append([]string{}, eventColumns...),
+				csvUint(res.Position),
+				csvUint(res.DriverID),
+				csvText(res.DriverName),
+				csvText(res.TeamName),
+				csvUint(res.Points),
+				res.Status,
+				csvBool(res.FastestLap),
+				bestLap,
+				laps,
+			))
+		}
+	}
+
+	return rows
+}
+
+// convertStandingsToCSV puts the driver and the team table below each other. The kind
+// column tells them apart.
+func convertStandingsToCSV(standings standingsResponse) [][]string {
+	rows := [][]string{{"kind", "position", "id", "name", "team_name", "points", "gross_points", "wins"}}
+
+	for _, row := range standings.Drivers {
+		rows = append(rows, []string{
+			"driver",
+			csvUint(row.Position),
+			csvUint(row.ID),
+			csvText(row.Name),
+			csvText(row.TeamName),
+			csvUint(row.Points),
+			csvUint(row.GrossPoints),
+			csvUint(row.Wins),
+		})
+	}
+	for _, row := range standings.Teams {
+		rows = append(rows, []string{
+			"team",
+			csvUint(row.Position),
+			csvUint(row.ID),
+			csvText(row.Name),
+			"",
+			csvUint(row.Points),
+			csvUint(row.GrossPoints),
+			csvUint(row.Wins),
+		})
+	}
+
+	return rows
+}
+
+// formatLapTime formats milliseconds as m:ss.SSS.
+func formatLapTime(ms uint64) string {
+	return fmt.Sprintf("%d:%02d.%03d", ms/60000, ms/1000%60, ms%1000)
+}
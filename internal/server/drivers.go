@@ -0,0 +1,110 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func GetDriverStatsHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		driverID, err := strconv.Atoi(ctx.Param("driver_id"))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		_, driverNameMap, err := buildNameMaps(repo)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to generate name maps")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		branding, err := buildBrandingMaps(repo)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to generate branding maps")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		if _, ok := driverNameMap[uint64(driverID)]; !ok {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		events, ok := loadFilteredEvents(ctx, repo)
+		if !ok {
+			return
+		}
+
+		incidents, err := repo.ListIncidents()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read incidents")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		eventIDs := make([]uint64, 0, len(events))
+		for _, e := range events {
+			eventIDs = append(eventIDs, e.ID)
+		}
+		incidents = filterIncidents(incidents, func(i jsondb.Incident) bool {
+			return IDisInList(eventIDs, i.EventID)
+		})
+
+		resp := convertDriverStatsToResponse(uint64(driverID), events, driverNameMap, branding)
+		resp.Incidents = countIncidents(incidents, uint64(driverID))
+		resp.Conditions = driverConditionsResponse{
+			Dry: convertConditionStats(uint64(driverID), filterEvents(events, hasTrackState(jsondb.DryTrackState))),
+			Wet: convertConditionStats(uint64(driverID), filterEvents(events, hasTrackState(jsondb.WetTrackState))),
+		}
+		ctx.JSON(http.StatusOK, resp)
+	}
+}
+
+// loadFilteredEvents returns all completed events matching the optional season_id, track_state, weather
+// and type query parameters. Multiple types can be given by repeating the type parameter. Without a type
+// qualifying sessions are left out.
+func loadFilteredEvents(ctx *gin.Context, repo jsondb.JsonDatabase) ([]jsondb.RaceEvent, bool) {
+	events, err := repo.ListEvents()
+	if err != nil {
+		logrus.WithError(err).Warn("unable to read events")
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return nil, false
+	}
+
+	season, ok := querySeason(ctx, repo)
+	if !ok {
+		return nil, false
+	}
+	events = filterEvents(filterEvents(events, isInSeason(season)), isCompletedEvent)
+	events, ok = filterEventsByConditions(ctx, events)
+	if !ok {
+		return nil, false
+	}
+
+	typeParams := ctx.QueryArray("type")
+	if len(typeParams) <= 0 {
+		return filterEvents(events, func(e jsondb.RaceEvent) bool {
+			return !isQualifyingEvent(e)
+		}), true
+	}
+
+	types := make(map[jsondb.EventType]bool)
+	for _, typeParam := range typeParams {
+		eventType, err := strconv.Atoi(typeParam)
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return nil, false
+		}
+		types[jsondb.EventType(eventType)] = true
+	}
+
+	return filterEvents(events, func(e jsondb.RaceEvent) bool {
+		return types[e.Type]
+	}), true
+}
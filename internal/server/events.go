@@ -1,6 +1,7 @@
 package server
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -25,7 +26,38 @@ func GetEventsHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
 			return
 		}
 
-		eventResp := convertEventsToResponse(events, teamNameMap, driverNameMap)
+		branding, err := buildBrandingMaps(repo)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to generate branding maps")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		season, ok := querySeason(ctx, repo)
+		if !ok {
+			return
+		}
+		events, ok = filterEventsByConditions(ctx, filterEvents(events, isInSeason(season)))
+		if !ok {
+			return
+		}
+
+		if statusParam := ctx.Query("status"); statusParam != "" {
+			status, err := strconv.Atoi(statusParam)
+			if err != nil {
+				ctx.AbortWithStatus(http.StatusBadRequest)
+				return
+			}
+			events = filterEvents(events, func(e jsondb.RaceEvent) bool {
+				return e.Status == jsondb.EventStatus(status)
+			})
+		}
+
+		eventResp := convertEventsToResponse(events, teamNameMap, driverNameMap, branding)
+		if wantsCSV(ctx) {
+			respondCSV(ctx, "races.csv", convertEventsToCSV(eventResp))
+			return
+		}
 
 		ctx.JSON(http.StatusOK, eventResp)
 	}
@@ -46,6 +78,13 @@ func GetEventHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
 			return
 		}
 
+		branding, err := buildBrandingMaps(repo)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to generate branding maps")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
 		event, err := repo.GetEvent(uint64(id))
 		if err != nil {
 			logrus.WithError(err).Warn("unable to load single event")
@@ -53,7 +92,7 @@ func GetEventHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
 			return
 		}
 
-		eventResp := convertEventToResponse(*event, teamNameMap, driverNameMap)
+		eventResp := convertEventToResponse(*event, teamNameMap, driverNameMap, branding)
 		ctx.JSON(http.StatusOK, eventResp)
 	}
 }
@@ -67,7 +106,7 @@ func GetLatestEventHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
 			return
 		}
 
-		latest := getLatest(events)
+		latest := getLatest(filterEvents(events, isCompletedEvent))
 		if latest == nil {
 			ctx.AbortWithStatus(http.StatusNotFound)
 			return
@@ -80,7 +119,48 @@ func GetLatestEventHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
 			return
 		}
 
-		eventResp := convertEventToResponse(*latest, teamNameMap, driverNameMap)
+		branding, err := buildBrandingMaps(repo)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to generate branding maps")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		eventResp := convertEventToResponse(*latest, teamNameMap, driverNameMap, branding)
+		ctx.JSON(http.StatusOK, eventResp)
+	}
+}
+
+func GetNextEventHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		events, err := repo.ListEvents()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read events")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		next := getNext(events)
+		if next == nil {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		teamNameMap, driverNameMap, err := buildNameMaps(repo)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to generate name maps")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		branding, err := buildBrandingMaps(repo)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to generate branding maps")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		eventResp := convertEventToResponse(*next, teamNameMap, driverNameMap, branding)
 		ctx.JSON(http.StatusOK, eventResp)
 	}
 }
@@ -91,23 +171,241 @@ func getLatest(events []jsondb.RaceEvent) *jsondb.RaceEvent {
 	}
 
 	latestEvent := &events[0]
-	if len(events) > 1 {
-		for _, checkEvent := range events[1:] {
-			if checkEvent.Date > latestEvent.Date {
-				latestEvent = &checkEvent
-			}
+	for index := range events {
+		if events[index].Date > latestEvent.Date {
+			latestEvent = &events[index]
 		}
 	}
 
 	return latestEvent
 }
 
+// getNext returns the running event or the scheduled event with the earliest date.
+func getNext(events []jsondb.RaceEvent) *jsondb.RaceEvent {
+	var nextEvent *jsondb.RaceEvent
+	for index := range events {
+		checkEvent := &events[index]
+		if !isUpcomingEvent(*checkEvent) {
+			continue
+		}
+
+		if checkEvent.Status == jsondb.InProgressEventStatus {
+			return checkEvent
+		}
+		if nextEvent == nil || checkEvent.Date < nextEvent.Date {
+			nextEvent = checkEvent
+		}
+	}
+
+	return nextEvent
+}
+
+type eventStatusRequest struct {
+	Status jsondb.EventStatus `json:"status"`
+}
+
+func UpdateEventStatusHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userInput := &eventStatusRequest{}
+		if err := ctx.BindJSON(userInput); err != nil {
+			logrus.WithError(err).Warn("unable to get user input for event status")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		if userInput.Status > jsondb.DraftEventStatus {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		raceID, err := strconv.Atoi(ctx.Param("race_id"))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		event, err := repo.GetEvent(uint64(raceID))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		event.Status = userInput.Status
+		if err := repo.UpdateEvent(event); err != nil {
+			logrus.WithError(err).Warn("unable to update event status")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		ctx.Status(http.StatusOK)
+	}
+}
+
 type raceEventRequest struct {
-	Name         string           `json:"name"`
-	Date         int64            `json:"race_date_unix"`
-	Type         jsondb.EventType `json:"type"`
-	StartingGrid []uint64         `json:"starting_grid"`
-	Results      []uint64         `json:"results"`
+	Name         string                    `json:"name"`
+	Date         int64                     `json:"race_date_unix"`
+	Type         jsondb.EventType          `json:"type"`
+	Status       *jsondb.EventStatus       `json:"status"`
+	SeasonID     uint64                    `json:"season_id"`
+	StartingGrid []uint64                  `json:"starting_grid"`
+	Results      []uint64                  `json:"results"`
+	Lineups      []jsondb.EventLineup      `json:"lineups"`
+	FastestLap   *uint64                   `json:"fastest_lap_driver_id"`
+	DNF          []uint64                  `json:"dnf"`
+	DSQ          []uint64                  `json:"dsq"`
+	ClassEntries []jsondb.ClassEntry       `json:"class_entries"`
+	DivisionID   uint64                    `json:"division_id"`
+	Strategies   []driverStrategyRequest   `json:"strategies"`
+	Conditions   *jsondb.SessionConditions `json:"conditions"`
+}
+
+type driverStrategyRequest struct {
+	DriverID      uint64              `json:"driver_id"`
+	Laps          uint64              `json:"laps"`
+	StartCompound jsondb.TyreCompound `json:"start_compound"`
+	PitStops      []jsondb.PitStop    `json:"pit_stops"`
+}
+
+type driverAssignment struct {
+	TeamID     uint64
+	Substitute bool
+	ClassID    uint64
+}
+
+func buildRaceEvent(userInput *raceEventRequest, teams []jsondb.Team, season *jsondb.Season) (*jsondb.RaceEvent, error) {
+	assignments, err := buildDriverAssignments(teams, userInput.Lineups)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := assignClasses(assignments, season, userInput.ClassEntries); err != nil {
+		return nil, err
+	}
+
+	if userInput.DivisionID != 0 {
+		if season == nil {
+			return nil, fmt.Errorf("division needs a season")
+		}
+		if _, ok := season.GetDivision(userInput.DivisionID); !ok {
+			return nil, fmt.Errorf("unknown division %d", userInput.DivisionID)
+		}
+	}
+
+	if userInput.Conditions != nil {
+		if err := validateConditions(userInput.Conditions); err != nil {
+			return nil, err
+		}
+	}
+
+	newRaceEvent := &jsondb.RaceEvent{
+		Name:         userInput.Name,
+		Date:         userInput.Date,
+		Type:         userInput.Type,
+		SeasonID:     userInput.SeasonID,
+		StartingGrid: make([]jsondb.RacePosition, 0),
+		Results:      make([]jsondb.RacePosition, 0),
+		Lineups:      userInput.Lineups,
+		ClassEntries: userInput.ClassEntries,
+		DivisionID:   userInput.DivisionID,
+		Conditions:   userInput.Conditions,
+	}
+
+	// overwrite team IDs based on driver ID to make user input easier
+	for index, driverID := range userInput.StartingGrid {
+		newRaceEvent.StartingGrid = append(newRaceEvent.StartingGrid, jsondb.RacePosition{
+			Position:   uint64(index + 1),
+			DriverID:   driverID,
+			TeamID:     assignments[driverID].TeamID,
+			Substitute: assignments[driverID].Substitute,
+			ClassID:    assignments[driverID].ClassID,
+		})
+	}
+
+	newRaceEvent.Results = buildResults(userInput.Results, newRaceEvent.Type, assignments)
+	applyResultFlags(newRaceEvent.Results, userInput.FastestLap, userInput.DNF, userInput.DSQ)
+	if err := applyStrategies(newRaceEvent.Results, userInput.Strategies); err != nil {
+		return nil, err
+	}
+
+	// new events without an explicit status are completed once they have results
+	if userInput.Status != nil {
+		if *userInput.Status > jsondb.DraftEventStatus {
+			return nil, fmt.Errorf("unknown event status %d", *userInput.Status)
+		}
+		newRaceEvent.Status = *userInput.Status
+	} else if len(newRaceEvent.Results) <= 0 {
+		newRaceEvent.Status = jsondb.ScheduledEventStatus
+	}
+
+	return newRaceEvent, nil
+}
+
+// buildDriverAssignments maps every driver to the team they drive for. Drivers default
+// to the team of their roster and are moved by the event lineups.
+func buildDriverAssignments(teams []jsondb.Team, lineups []jsondb.EventLineup) (map[uint64]driverAssignment, error) {
+	assignments := make(map[uint64]driverAssignment)
+	raceDriverTeams := make(map[uint64]uint64)
+	teamIDs := make(map[uint64]bool)
+	for _, t := range teams {
+		teamIDs[t.ID] = true
+		for _, d := range t.Drivers {
+			assignments[d.ID] = driverAssignment{TeamID: t.ID, Substitute: d.Role != jsondb.RaceDriverRole}
+			if d.Role == jsondb.RaceDriverRole {
+				raceDriverTeams[d.ID] = t.ID
+			}
+		}
+	}
+
+	lineupDrivers := make(map[uint64]bool)
+	for _, lineup := range lineups {
+		if !teamIDs[lineup.TeamID] {
+			return nil, fmt.Errorf("lineup for unknown team %d", lineup.TeamID)
+		}
+
+		for _, driverID := range lineup.DriverIDs {
+			if _, ok := assignments[driverID]; !ok {
+				return nil, fmt.Errorf("lineup contains unknown driver %d", driverID)
+			}
+			if lineupDrivers[driverID] {
+				return nil, fmt.Errorf("driver %d is part of multiple lineups", driverID)
+			}
+			lineupDrivers[driverID] = true
+
+			rosterTeamID, isRaceDriver := raceDriverTeams[driverID]
+			assignments[driverID] = driverAssignment{
+				TeamID:     lineup.TeamID,
+				Substitute: !isRaceDriver || rosterTeamID != lineup.TeamID,
+			}
+		}
+	}
+
+	return assignments, nil
+}
+
+// assignClasses sets the car class of every driver from the season defaults and the
+// class entries of the event itself.
+func assignClasses(assignments map[uint64]driverAssignment, season *jsondb.Season, eventEntries []jsondb.ClassEntry) error {
+	if season == nil {
+		if len(eventEntries) > 0 {
+			return fmt.Errorf("class entries need a season")
+		}
+		return nil
+	}
+
+	if err := validateClassEntries(season, eventEntries); err != nil {
+		return err
+	}
+
+	for _, entries := range [][]jsondb.ClassEntry{season.ClassEntries, eventEntries} {
+		for _, entry := range entries {
+			if assignment, ok := assignments[entry.DriverID]; ok {
+				assignment.ClassID = entry.ClassID
+				assignments[entry.DriverID] = assignment
+			}
+		}
+	}
+
+	return nil
 }
 
 func CreateRaceEventHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
@@ -120,38 +418,30 @@ func CreateRaceEventHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
 			return
 		}
 
-		newRaceEvent := &jsondb.RaceEvent{
-			Name:         userInput.Name,
-			Date:         userInput.Date,
-			Type:         userInput.Type,
-			StartingGrid: make([]jsondb.RacePosition, 0),
-			Results:      make([]jsondb.RacePosition, 0),
-		}
-
 		teams, err := repo.ListTeams()
 		if err != nil {
 			logrus.WithError(err).Warn("unable to read teams for adding event")
 			ctx.AbortWithStatus(http.StatusInternalServerError)
 			return
 		}
-		driverToTeamMap := make(map[uint64]uint64)
-		for _, t := range teams {
-			for _, d := range t.Drivers {
-				driverToTeamMap[d.ID] = t.ID
+
+		var season *jsondb.Season
+		if userInput.SeasonID != 0 {
+			season, err = repo.GetSeason(userInput.SeasonID)
+			if err != nil {
+				logrus.WithError(err).Warn("event references unknown season")
+				ctx.AbortWithStatus(http.StatusBadRequest)
+				return
 			}
 		}
 
-		// overwrite team IDs based on driver ID to make user input easier
-		for index, driverID := range userInput.StartingGrid {
-			newRaceEvent.StartingGrid = append(newRaceEvent.StartingGrid, jsondb.RacePosition{
-				Position: uint64(index + 1),
-				DriverID: driverID,
-				TeamID:   driverToTeamMap[driverID],
-			})
+		newRaceEvent, err := buildRaceEvent(userInput, teams, season)
+		if err != nil {
+			logrus.WithError(err).Warn("invalid user input for new event")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
 		}
 
-		newRaceEvent.Results = buildResults(userInput.Results, newRaceEvent.Type, driverToTeamMap)
-
 		if err := repo.AddEvent(newRaceEvent); err != nil {
 			logrus.WithError(err).Warn("unable to add race event")
 			ctx.AbortWithStatus(http.StatusInternalServerError)
@@ -178,13 +468,23 @@ func UpdateRaceEventHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
 			return
 		}
 
-		newRaceEvent := &jsondb.RaceEvent{
-			ID:           uint64(raceID),
-			Name:         userInput.Name,
-			Date:         userInput.Date,
-			Type:         userInput.Type,
-			StartingGrid: make([]jsondb.RacePosition, 0),
-			Results:      make([]jsondb.RacePosition, 0),
+		existing, err := repo.GetEvent(uint64(raceID))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		// without an explicit status upcoming events are completed once they have results, all
+		// others keep their status so drafts are not completed by accident
+		if userInput.Status == nil {
+			status := existing.Status
+			if isUpcomingEvent(*existing) && len(userInput.Results) > 0 {
+				status = jsondb.CompletedEventStatus
+			}
+			userInput.Status = &status
+		}
+		if userInput.Conditions == nil {
+			userInput.Conditions = existing.Conditions
 		}
 
 		teams, err := repo.ListTeams()
@@ -193,23 +493,47 @@ func UpdateRaceEventHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
 			ctx.AbortWithStatus(http.StatusInternalServerError)
 			return
 		}
-		driverToTeamMap := make(map[uint64]uint64)
-		for _, t := range teams {
-			for _, d := range t.Drivers {
-				driverToTeamMap[d.ID] = t.ID
+
+		var season *jsondb.Season
+		if userInput.SeasonID != 0 {
+			season, err = repo.GetSeason(userInput.SeasonID)
+			if err != nil {
+				logrus.WithError(err).Warn("event references unknown season")
+				ctx.AbortWithStatus(http.StatusBadRequest)
+				return
 			}
 		}
 
-		// overwrite team IDs based on driver ID to make user input easier
-		for index, driverID := range userInput.StartingGrid {
-			newRaceEvent.StartingGrid = append(newRaceEvent.StartingGrid, jsondb.RacePosition{
-				Position: uint64(index + 1),
-				DriverID: driverID,
-				TeamID:   driverToTeamMap[driverID],
-			})
+		newRaceEvent, err := buildRaceEvent(userInput, teams, season)
+		if err != nil {
+			logrus.WithError(err).Warn("invalid user input for event update")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
 		}
+		newRaceEvent.ID = existing.ID
 
-		newRaceEvent.Results = buildResults(userInput.Results, newRaceEvent.Type, driverToTeamMap)
+		// a generated grid stays auditable as long as the editor did not change it
+		if existing.GridGeneration != nil && sameGridOrder(existing.StartingGrid, newRaceEvent.StartingGrid) {
+			newRaceEvent.GridGeneration = existing.GridGeneration
+		}
+		newRaceEvent.ExternalID = existing.ExternalID
+
+		// best laps and incident points come from imports and can not be edited, drivers keep them
+		// as long as they are part of the results. The same goes for strategies that are not sent.
+		for index := range newRaceEvent.Results {
+			previous, ok := findPosition(existing.Results, newRaceEvent.Results[index].DriverID)
+			if !ok {
+				continue
+			}
+
+			newRaceEvent.Results[index].BestLapMS = previous.BestLapMS
+			newRaceEvent.Results[index].IncidentPoints = previous.IncidentPoints
+			if userInput.Strategies == nil {
+				newRaceEvent.Results[index].Laps = previous.Laps
+				newRaceEvent.Results[index].StartCompound = previous.StartCompound
+				newRaceEvent.Results[index].PitStops = previous.PitStops
+			}
+		}
 
 		if err := repo.UpdateEvent(newRaceEvent); err != nil {
 			logrus.WithError(err).Warn("unable to update event")
@@ -221,6 +545,7 @@ func UpdateRaceEventHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
 	}
 }
 
+// DeleteRaceEventHandler refuses to delete events that still have incidents or a report.
 func DeleteRaceEventHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		raceID, err := strconv.Atoi(ctx.Param("race_id"))
@@ -229,6 +554,32 @@ func DeleteRaceEventHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
 			return
 		}
 
+		incidents, err := repo.ListIncidents()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read incidents")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		for _, i := range incidents {
+			if i.EventID == uint64(raceID) {
+				ctx.AbortWithStatus(http.StatusConflict)
+				return
+			}
+		}
+
+		reports, err := repo.ListReports()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read reports")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		for _, r := range reports {
+			if r.EventID == uint64(raceID) {
+				ctx.AbortWithStatus(http.StatusConflict)
+				return
+			}
+		}
+
 		if err := repo.DeleteEvent(uint64(raceID)); err != nil {
 			logrus.WithError(err).Warn("unable to delete event")
 			ctx.AbortWithStatus(http.StatusInternalServerError)
@@ -239,26 +590,113 @@ func DeleteRaceEventHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
 	}
 }
 
-func buildResults(input []uint64, eventType jsondb.EventType, driverToTeamMap map[uint64]uint64) []jsondb.RacePosition {
+func buildResults(input []uint64, eventType jsondb.EventType, assignments map[uint64]driverAssignment) []jsondb.RacePosition {
 	res := make([]jsondb.RacePosition, 0, len(input))
+	classCounts := make(map[uint64]int)
 	for index, driverID := range input {
-		var points uint64 = 0
-		if eventType == jsondb.SprintEventType || eventType == jsondb.PreSeasonSprintType {
-			points = getSprintPointsByIndex(index)
-		} else if eventType == jsondb.RaceEventType || eventType == jsondb.PreSeason {
-			points = getRacePointsByIndex(index)
-		}
-		res = append(res, jsondb.RacePosition{
-			Position: uint64(index + 1),
-			Points:   points,
-			DriverID: driverID,
-			TeamID:   driverToTeamMap[driverID],
-		})
+		assignment := assignments[driverID]
+		pos := jsondb.RacePosition{
+			Position:   uint64(index + 1),
+			Points:     getPointsByIndex(eventType, index),
+			DriverID:   driverID,
+			TeamID:     assignment.TeamID,
+			Substitute: assignment.Substitute,
+		}
+
+		// every class is scored like a separate race inside of the event
+		if assignment.ClassID != 0 {
+			classIndex := classCounts[assignment.ClassID]
+			classCounts[assignment.ClassID]++
+
+			pos.ClassID = assignment.ClassID
+			pos.ClassPosition = uint64(classIndex + 1)
+			pos.ClassPoints = getPointsByIndex(eventType, classIndex)
+		}
+
+		res = append(res, pos)
 	}
 
 	return res
 }
 
+func getPointsByIndex(eventType jsondb.EventType, index int) uint64 {
+	if eventType == jsondb.SprintEventType || eventType == jsondb.PreSeasonSprintType {
+		return getSprintPointsByIndex(index)
+	} else if eventType == jsondb.RaceEventType || eventType == jsondb.PreSeason {
+		return getRacePointsByIndex(index)
+	}
+
+	return 0
+}
+
+// applyResultFlags marks the fastest lap and retirements. Disqualified drivers keep their
+// classification but lose all points of the event.
+func applyResultFlags(results []jsondb.RacePosition, fastestLap *uint64, dnf []uint64, dsq []uint64) {
+	for index := range results {
+		res := &results[index]
+		if fastestLap != nil && *fastestLap == res.DriverID {
+			res.FastestLap = true
+		}
+		if IDisInList(dnf, res.DriverID) {
+			res.Status = jsondb.DNFResultStatus
+		}
+		if IDisInList(dsq, res.DriverID) {
+			res.Status = jsondb.DSQResultStatus
+			res.Points = 0
+			res.ClassPoints = 0
+		}
+	}
+}
+
+// applyStrategies adds laps, tyres and pit stops to the results of the drivers.
+func applyStrategies(results []jsondb.RacePosition, strategies []driverStrategyRequest) error {
+	applied := make([]uint64, 0, len(strategies))
+	for _, strategy := range strategies {
+		if IDisInList(applied, strategy.DriverID) {
+			return fmt.Errorf("multiple strategies for driver %d", strategy.DriverID)
+		}
+		applied = append(applied, strategy.DriverID)
+
+		index := -1
+		for resIndex, res := range results {
+			if res.DriverID == strategy.DriverID {
+				index = resIndex
+				break
+			}
+		}
+		if index < 0 {
+			return fmt.Errorf("strategy for driver %d without result", strategy.DriverID)
+		}
+
+		if strategy.StartCompound > jsondb.WetTyreCompound {
+			return fmt.Errorf("unknown tyre compound %d", strategy.StartCompound)
+		}
+
+		var prevLap uint64
+		for _, stop := range strategy.PitStops {
+			if stop.Lap <= prevLap {
+				return fmt.Errorf("pit stops of driver %d are not in lap order", strategy.DriverID)
+			}
+			if strategy.Laps > 0 && stop.Lap > strategy.Laps {
+				return fmt.Errorf("pit stop of driver %d after the last lap", strategy.DriverID)
+			}
+			if stop.Compound > jsondb.WetTyreCompound {
+				return fmt.Errorf("unknown tyre compound %d", stop.Compound)
+			}
+			if stop.PenaltyServedMS > stop.StationaryTimeMS {
+				return fmt.Errorf("served penalty of driver %d is longer than the stop", strategy.DriverID)
+			}
+			prevLap = stop.Lap
+		}
+
+		results[index].Laps = strategy.Laps
+		results[index].StartCompound = strategy.StartCompound
+		results[index].PitStops = strategy.PitStops
+	}
+
+	return nil
+}
+
 func getSprintPointsByIndex(index int) uint64 {
 	points := 8 - index
 	if points > 0 {
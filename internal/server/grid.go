@@ -0,0 +1,272 @@
+package server
+
+import (
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type gridGenerationRequest struct {
+	Rule        jsondb.GridRule `json:"rule"`
+	ReverseTopN uint64          `json:"reverse_top_n"`
+	Seed        *int64          `json:"seed"`
+}
+
+// GenerateGridHandler builds the starting grid of an event from the current standings or
+// a seeded random draw and stores the used rule with the event.
+func GenerateGridHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userInput := &gridGenerationRequest{}
+		if err := ctx.BindJSON(userInput); err != nil {
+			logrus.WithError(err).Warn("unable to get user input for grid generation")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		raceID, err := strconv.Atoi(ctx.Param("race_id"))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		event, err := repo.GetEvent(uint64(raceID))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		teams, err := repo.ListTeams()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read teams")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		events, err := repo.ListEvents()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read events")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		settings, err := repo.GetSettings()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read settings")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		var season *jsondb.Season
+		if event.SeasonID != 0 {
+			season, err = repo.GetSeason(event.SeasonID)
+			if err != nil {
+				logrus.WithError(err).Warn("unable to read season of event")
+				ctx.AbortWithStatus(http.StatusInternalServerError)
+				return
+			}
+		}
+
+		generation := &jsondb.GridGeneration{
+			Rule:        userInput.Rule,
+			ReverseTopN: userInput.ReverseTopN,
+			GeneratedAt: time.Now().Unix(),
+		}
+
+		entrants := eventEntrants(*event, teams, season)
+		var order []uint64
+		switch userInput.Rule {
+		case jsondb.StandingsGridRule, jsondb.ReverseStandingsGridRule:
+			standingsEvents := gridStandingsEvents(*event, events)
+			config := standingsConfig{Settings: *settings}
+			if season != nil {
+				config.DropRule = season.DropRule
+			}
+			table := buildStandings(teams, standingsEvents, config)
+
+			order = orderByStandings(entrants, table.Drivers)
+			if userInput.Rule == jsondb.ReverseStandingsGridRule {
+				order = reverseTopN(order, userInput.ReverseTopN)
+			}
+
+			generation.StandingsEventIDs = make([]uint64, 0, len(standingsEvents))
+			for _, e := range standingsEvents {
+				generation.StandingsEventIDs = append(generation.StandingsEventIDs, e.ID)
+			}
+		case jsondb.RandomGridRule:
+			generation.Seed = time.Now().UnixNano()
+			if userInput.Seed != nil {
+				generation.Seed = *userInput.Seed
+			}
+			order = randomDraw(entrants, generation.Seed)
+		default:
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		assignments, err := buildDriverAssignments(teams, event.Lineups)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to assign drivers of event")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		if err := assignClasses(assignments, season, event.ClassEntries); err != nil {
+			logrus.WithError(err).Warn("unable to assign classes of event")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		event.StartingGrid = buildGrid(order, assignments)
+		event.GridGeneration = generation
+
+		if err := repo.UpdateEvent(event); err != nil {
+			logrus.WithError(err).Warn("unable to update event with generated grid")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		teamNameMap, driverNameMap, err := buildNameMaps(repo)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to generate name maps")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		branding, err := buildBrandingMaps(repo)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to generate branding maps")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, convertEventToResponse(*event, teamNameMap, driverNameMap, branding))
+	}
+}
+
+func buildGrid(order []uint64, assignments map[uint64]driverAssignment) []jsondb.RacePosition {
+	grid := make([]jsondb.RacePosition, 0, len(order))
+	for index, driverID := range order {
+		grid = append(grid, jsondb.RacePosition{
+			Position:   uint64(index + 1),
+			DriverID:   driverID,
+			TeamID:     assignments[driverID].TeamID,
+			Substitute: assignments[driverID].Substitute,
+			ClassID:    assignments[driverID].ClassID,
+		})
+	}
+
+	return grid
+}
+
+// eventEntrants returns the drivers taking part in an event sorted by ID. These are the
+// division roster for division events and otherwise all race drivers, with teams that
+// have a lineup for the event using the drivers of that lineup instead.
+func eventEntrants(event jsondb.RaceEvent, teams []jsondb.Team, season *jsondb.Season) []uint64 {
+	entrants := make([]uint64, 0)
+	if season != nil && event.DivisionID != 0 {
+		if division, ok := season.GetDivision(event.DivisionID); ok {
+			entrants = append(entrants, division.DriverIDs...)
+		}
+	} else {
+		lineupTeams := make(map[uint64]bool)
+		for _, lineup := range event.Lineups {
+			lineupTeams[lineup.TeamID] = true
+			entrants = append(entrants, lineup.DriverIDs...)
+		}
+
+		for _, t := range teams {
+			if lineupTeams[t.ID] {
+				continue
+			}
+			for _, d := range t.Drivers {
+				if d.Role == jsondb.RaceDriverRole {
+					entrants = append(entrants, d.ID)
+				}
+			}
+		}
+	}
+
+	sort.Slice(entrants, func(i, j int) bool {
+		return entrants[i] < entrants[j]
+	})
+
+	return entrants
+}
+
+// gridStandingsEvents are all completed championship events of the same season and division that
+// took place before the given event.
+func gridStandingsEvents(event jsondb.RaceEvent, events []jsondb.RaceEvent) []jsondb.RaceEvent {
+	return filterEvents(events, func(e jsondb.RaceEvent) bool {
+		return e.ID != event.ID &&
+			e.SeasonID == event.SeasonID &&
+			e.DivisionID == event.DivisionID &&
+			e.Date < event.Date &&
+			isCompletedEvent(e) &&
+			isChampionshipEvent(e)
+	})
+}
+
+// orderByStandings sorts the entrants by their standings position. Entrants without a
+// standings entry keep their order at the end of the grid.
+func orderByStandings(entrants []uint64, rows []*standingsRow) []uint64 {
+	order := make([]uint64, 0, len(entrants))
+	for _, row := range rows {
+		if IDisInList(entrants, row.ID) {
+			order = append(order, row.ID)
+		}
+	}
+
+	for _, driverID := range entrants {
+		if !IDisInList(order, driverID) {
+			order = append(order, driverID)
+		}
+	}
+
+	return order
+}
+
+// reverseTopN reverses the first n entries of the order. 0 reverses the whole order.
+func reverseTopN(order []uint64, n uint64) []uint64 {
+	if n == 0 || n > uint64(len(order)) {
+		n = uint64(len(order))
+	}
+
+	reversed := make([]uint64, len(order))
+	copy(reversed, order)
+	for i, j := 0, int(n)-1; i < j; i, j = i+1, j-1 {
+		reversed[i], reversed[j] = reversed[j], reversed[i]
+	}
+
+	return reversed
+}
+
+func randomDraw(entrants []uint64, seed int64) []uint64 {
+	order := make([]uint64, len(entrants))
+	copy(order, entrants)
+
+	rng := rand.New(rand.NewSource(seed))
+	rng.Shuffle(len(order), func(i, j int) {
+		order[i], order[j] = order[j], order[i]
+	})
+
+	return order
+}
+
+func sameGridOrder(a, b []jsondb.RacePosition) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for index := range a {
+		if a[index].DriverID != b[index].DriverID {
+			return false
+		}
+	}
+
+	return true
+}
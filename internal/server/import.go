@@ -0,0 +1,276 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	maxImportRequestSize = 10 << 20
+	importFormField      = "file"
+)
+
+// ImportOptions are applied to every event created by an import. Without a name the events
+// are named after the track and without a date the time of the import is used.
+type ImportOptions struct {
+	SeasonID uint64
+	Name     string
+	Date     int64
+}
+
+// UnmatchedDriver is a driver of an imported file that belongs to none of our drivers.
+// It has to be resolved with an alias before the file can be imported.
+type UnmatchedDriver struct {
+	Session   string `json:"session"`
+	CarNumber uint64 `json:"car_number,omitempty"`
+	Name      string `json:"name"`
+	PlayerID  string `json:"player_id,omitempty"`
+}
+
+// importedSession is a session of an uploaded results file turned into an event. Existing is
+// the stored event the import would update.
+type importedSession struct {
+	Session  string
+	Event    *jsondb.RaceEvent
+	Existing *jsondb.RaceEvent
+	Warnings []string
+}
+
+func newImportEventRequest(trackName string, eventType jsondb.EventType, options ImportOptions) *raceEventRequest {
+	draft := jsondb.DraftEventStatus
+	userInput := &raceEventRequest{
+		Name:     options.Name,
+		Date:     options.Date,
+		Type:     eventType,
+		Status:   &draft,
+		SeasonID: options.SeasonID,
+		Results:  make([]uint64, 0),
+	}
+
+	if userInput.Name == "" {
+		userInput.Name = trackName
+	}
+	if eventType == jsondb.QualifyingEventType {
+		userInput.Name = fmt.Sprintf("%s %s", userInput.Name, eventType.Name())
+	}
+	if userInput.Date == 0 {
+		userInput.Date = time.Now().Unix()
+	}
+
+	return userInput
+}
+
+// newTargetEventRequest keeps everything of an existing event but its grid and results.
+// Completed events stay completed, all others become drafts.
+func newTargetEventRequest(target *jsondb.RaceEvent) *raceEventRequest {
+	status := jsondb.DraftEventStatus
+	if target.Status == jsondb.CompletedEventStatus {
+		status = target.Status
+	}
+	classEntries := make([]jsondb.ClassEntry, len(target.ClassEntries))
+	copy(classEntries, target.ClassEntries)
+
+	return &raceEventRequest{
+		Name:         target.Name,
+		Date:         target.Date,
+		Type:         target.Type,
+		Status:       &status,
+		SeasonID:     target.SeasonID,
+		Results:      make([]uint64, 0),
+		Lineups:      target.Lineups,
+		ClassEntries: classEntries,
+		DivisionID:   target.DivisionID,
+		Conditions:   target.Conditions,
+	}
+}
+
+// buildImportedEvents validates all events of an import before any of them is stored.
+func buildImportedEvents(
+	requests []*raceEventRequest,
+	bestLaps []map[uint64]uint64,
+	teams []jsondb.Team,
+	season *jsondb.Season,
+) ([]*jsondb.RaceEvent, error) {
+	events := make([]*jsondb.RaceEvent, 0, len(requests))
+	for index, userInput := range requests {
+		event, err := buildRaceEvent(userInput, teams, season)
+		if err != nil {
+			return nil, err
+		}
+
+		for resIndex := range event.Results {
+			event.Results[resIndex].BestLapMS = bestLaps[index][event.Results[resIndex].DriverID]
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+func storeImportedEvents(repo jsondb.JsonDatabase, events []*jsondb.RaceEvent) error {
+	for _, event := range events {
+		if err := repo.AddEvent(event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func loadImportContext(repo jsondb.JsonDatabase, seasonID uint64) ([]jsondb.Team, *jsondb.Season, *driverMatcher, error) {
+	teams, err := repo.ListTeams()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var season *jsondb.Season
+	if seasonID != 0 {
+		season, err = repo.GetSeason(seasonID)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	aliases, err := repo.ListAliases()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return teams, season, buildDriverMatcher(aliases, teams), nil
+}
+
+// bindImportOptions reads the optional season_id, name and race_date_unix form fields.
+func bindImportOptions(ctx *gin.Context) (ImportOptions, bool) {
+	options := ImportOptions{Name: ctx.PostForm("name")}
+
+	if seasonParam := ctx.PostForm("season_id"); seasonParam != "" {
+		seasonID, err := strconv.Atoi(seasonParam)
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return options, false
+		}
+		options.SeasonID = uint64(seasonID)
+	}
+
+	if dateParam := ctx.PostForm("race_date_unix"); dateParam != "" {
+		date, err := strconv.ParseInt(dateParam, 10, 64)
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return options, false
+		}
+		options.Date = date
+	}
+
+	return options, true
+}
+
+func readImportFiles(ctx *gin.Context) ([][]byte, bool) {
+	form, err := ctx.MultipartForm()
+	if err != nil {
+		logrus.WithError(err).Warn("unable to read import upload")
+		ctx.AbortWithStatus(http.StatusBadRequest)
+		return nil, false
+	}
+
+	headers := form.File[importFormField]
+	if len(headers) <= 0 {
+		ctx.AbortWithStatus(http.StatusBadRequest)
+		return nil, false
+	}
+
+	files := make([][]byte, 0, len(headers))
+	for _, header := range headers {
+		file, err := header.Open()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to open uploaded file")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return nil, false
+		}
+
+		buf, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read uploaded file")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return nil, false
+		}
+		files = append(files, buf)
+	}
+
+	return files, true
+}
+
+func respondImportedEvents(ctx *gin.Context, repo jsondb.JsonDatabase, events []*jsondb.RaceEvent) {
+	teamNameMap, driverNameMap, err := buildNameMaps(repo)
+	if err != nil {
+		logrus.WithError(err).Warn("unable to generate name maps")
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	branding, err := buildBrandingMaps(repo)
+	if err != nil {
+		logrus.WithError(err).Warn("unable to generate branding maps")
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	stored := make([]jsondb.RaceEvent, 0, len(events))
+	for _, event := range events {
+		stored = append(stored, *event)
+	}
+
+	ctx.JSON(http.StatusCreated, convertEventsToResponse(stored, teamNameMap, driverNameMap, branding))
+}
+
+// convertCompoundName maps the compound names of sims to our compounds.
+func convertCompoundName(name string) jsondb.TyreCompound {
+	name = strings.ToLower(name)
+	switch {
+	case strings.Contains(name, "soft"):
+		return jsondb.SoftTyreCompound
+	case strings.Contains(name, "medium"):
+		return jsondb.MediumTyreCompound
+	case strings.Contains(name, "hard"):
+		return jsondb.HardTyreCompound
+	case strings.Contains(name, "inter"):
+		return jsondb.IntermediateTyreCompound
+	case strings.Contains(name, "wet"), strings.Contains(name, "rain"):
+		return jsondb.WetTyreCompound
+	default:
+		return jsondb.UnknownTyreCompound
+	}
+}
+
+func findClassByName(season *jsondb.Season, name string) (uint64, bool) {
+	if season == nil {
+		return 0, false
+	}
+
+	for _, class := range season.Classes {
+		if strings.EqualFold(strings.TrimSpace(class.Name), strings.TrimSpace(name)) {
+			return class.ID, true
+		}
+	}
+
+	return 0, false
+}
+
+func setClassEntry(entries []jsondb.ClassEntry, driverID uint64, classID uint64) []jsondb.ClassEntry {
+	for index := range entries {
+		if entries[index].DriverID == driverID {
+			entries[index].ClassID = classID
+			return entries
+		}
+	}
+
+	return append(entries, jsondb.ClassEntry{DriverID: driverID, ClassID: classID})
+}
@@ -0,0 +1,196 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+	"github.com/devnull-twitch/nyooom-backend/pkg/results/acc"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// ImportACCResultsHandler creates draft events from one or more uploaded ACC results files.
+// If any driver can not be matched nothing is saved and the unmatched drivers are returned.
+func ImportACCResultsHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportRequestSize)
+
+		options, ok := bindImportOptions(ctx)
+		if !ok {
+			return
+		}
+
+		files, ok := readImportFiles(ctx)
+		if !ok {
+			return
+		}
+
+		results, err := parseACCFiles(files)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to parse acc results file")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		teams, season, matcher, err := loadImportContext(repo, options.SeasonID)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to load import context")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		events, unmatched, err := buildACCEvents(results, teams, season, matcher, options)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to import acc results")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		if len(unmatched) > 0 {
+			ctx.JSON(http.StatusUnprocessableEntity, unmatchedDriversResponse{Unmatched: unmatched})
+			return
+		}
+
+		if err := storeImportedEvents(repo, events); err != nil {
+			logrus.WithError(err).Warn("unable to store imported events")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		respondImportedEvents(ctx, repo, events)
+	}
+}
+
+// ImportACCResults stores the sessions of the results files as draft events. Nothing is
+// stored if a driver could not be matched.
+func ImportACCResults(repo jsondb.JsonDatabase, files [][]byte, options ImportOptions) ([]*jsondb.RaceEvent, []UnmatchedDriver, error) {
+	results, err := parseACCFiles(files)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	teams, season, matcher, err := loadImportContext(repo, options.SeasonID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	events, unmatched, err := buildACCEvents(results, teams, season, matcher, options)
+	if err != nil || len(unmatched) > 0 {
+		return nil, unmatched, err
+	}
+
+	if err := storeImportedEvents(repo, events); err != nil {
+		return nil, nil, err
+	}
+
+	return events, nil, nil
+}
+
+// buildACCEvents turns every qualifying and race session into an event. Race sessions use the
+// order of a qualifying session of the same race weekend as starting grid.
+func buildACCEvents(
+	results []*acc.Result,
+	teams []jsondb.Team,
+	season *jsondb.Season,
+	matcher *driverMatcher,
+	options ImportOptions,
+) ([]*jsondb.RaceEvent, []UnmatchedDriver, error) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].RaceWeekendIndex != results[j].RaceWeekendIndex {
+			return results[i].RaceWeekendIndex < results[j].RaceWeekendIndex
+		}
+		return results[i].SessionIndex < results[j].SessionIndex
+	})
+
+	unmatched := make([]UnmatchedDriver, 0)
+	requests := make([]*raceEventRequest, 0, len(results))
+	bestLaps := make([]map[uint64]uint64, 0, len(results))
+	qualifyingOrders := make(map[int][]uint64)
+	for _, result := range results {
+		var eventType jsondb.EventType
+		switch result.SessionType {
+		case acc.QualifyingSessionType:
+			eventType = jsondb.QualifyingEventType
+		case acc.RaceSessionType:
+			eventType = jsondb.RaceEventType
+		default:
+			return nil, nil, fmt.Errorf("session type %s can not be imported", result.SessionType)
+		}
+
+		userInput := newImportEventRequest(result.TrackName, eventType, options)
+		sessionBestLaps := make(map[uint64]uint64)
+		var fastestLapMS uint64
+		for _, line := range result.SessionResult.LeaderBoardLines {
+			driver := line.CurrentDriver
+			if len(line.Car.Drivers) > 0 {
+				driver = line.Car.Drivers[0]
+			}
+
+			driverID, ok := matcher.matchAlias(jsondb.SteamAliasType, driver.PlayerID)
+			if !ok {
+				driverID, ok = matcher.matchName(driver.FullName())
+			}
+			if !ok {
+				unmatched = append(unmatched, UnmatchedDriver{
+					Session:   fmt.Sprintf("%s %s", result.TrackName, eventType.Name()),
+					CarNumber: line.Car.RaceNumber,
+					Name:      driver.FullName(),
+					PlayerID:  driver.PlayerID,
+				})
+				continue
+			}
+			if IDisInList(userInput.Results, driverID) {
+				return nil, nil, fmt.Errorf("driver %d matched multiple cars in %s", driverID, result.TrackName)
+			}
+
+			userInput.Results = append(userInput.Results, driverID)
+			userInput.Strategies = append(userInput.Strategies, driverStrategyRequest{
+				DriverID: driverID,
+				Laps:     line.Timing.LapCount,
+			})
+
+			if line.Timing.HasBestLap() {
+				sessionBestLaps[driverID] = line.Timing.BestLap
+				if eventType == jsondb.RaceEventType && (fastestLapMS == 0 || line.Timing.BestLap < fastestLapMS) {
+					fastestLapMS = line.Timing.BestLap
+					fastestDriverID := driverID
+					userInput.FastestLap = &fastestDriverID
+				}
+			}
+		}
+
+		if eventType == jsondb.QualifyingEventType {
+			qualifyingOrders[result.RaceWeekendIndex] = userInput.Results
+		} else {
+			for _, driverID := range qualifyingOrders[result.RaceWeekendIndex] {
+				if IDisInList(userInput.Results, driverID) {
+					userInput.StartingGrid = append(userInput.StartingGrid, driverID)
+				}
+			}
+		}
+
+		requests = append(requests, userInput)
+		bestLaps = append(bestLaps, sessionBestLaps)
+	}
+
+	if len(unmatched) > 0 {
+		return nil, unmatched, nil
+	}
+
+	events, err := buildImportedEvents(requests, bestLaps, teams, season)
+	return events, nil, err
+}
+
+func parseACCFiles(files [][]byte) ([]*acc.Result, error) {
+	results := make([]*acc.Result, 0, len(files))
+	for _, file := range files {
+		result, err := acc.Parse(file)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
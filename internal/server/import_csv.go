@@ -0,0 +1,619 @@
+package server
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// CSVLineError is a problem with a line of an uploaded CSV file. Line 1 is the header.
+type CSVLineError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// csvResultRow is a validated line of a classification CSV.
+type csvResultRow struct {
+	Line       int
+	Position   uint64
+	Grid       uint64
+	DriverID   uint64
+	Status     jsondb.ResultStatus
+	FastestLap bool
+	Laps       uint64
+	BestLapMS  uint64
+}
+
+// csvResultColumns holds the index of every known column or -1 if the file does not have it.
+type csvResultColumns struct {
+	Position   int
+	Grid       int
+	Driver     int
+	Status     int
+	FastestLap int
+	Laps       int
+	BestLap    int
+}
+
+// csvColumnNames lists the accepted header names of every column.
+var csvColumnNames = map[string][]string{
+	"position":    {"position", "pos", "p", "place"},
+	"grid":        {"grid", "start", "starting_position"},
+	"driver":      {"driver", "driver_name", "driver_id", "name"},
+	"status":      {"status", "result"},
+	"fastest_lap": {"fastest_lap", "fastest", "fl"},
+	"laps":        {"laps"},
+	"best_lap":    {"best_lap", "best_lap_time"},
+}
+
+// PreviewCSVResultsHandler validates a classification CSV for an event and shows the
+// changes it would make without saving anything.
+func PreviewCSVResultsHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		imported, ok := loadCSVImport(ctx, repo)
+		if !ok {
+			return
+		}
+
+		teamNameMap, driverNameMap, err := buildNameMaps(repo)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to generate name maps")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		branding, err := buildBrandingMaps(repo)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to generate branding maps")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		changes := diffEvents(*imported.Existing, *imported.Event, driverNameMap)
+		ctx.JSON(http.StatusOK, importPreviewSessionResponse{
+			Session:  imported.Session,
+			Action:   "update",
+			Event:    convertEventToResponse(*imported.Event, teamNameMap, driverNameMap, branding),
+			Changes:  &changes,
+			Warnings: imported.Warnings,
+		})
+	}
+}
+
+// ImportCSVResultsHandler replaces the grid and results of an event with a classification
+// CSV. Nothing is saved if any line of the file is invalid.
+func ImportCSVResultsHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		imported, ok := loadCSVImport(ctx, repo)
+		if !ok {
+			return
+		}
+
+		if err := repo.UpdateEvent(imported.Event); err != nil {
+			logrus.WithError(err).Warn("unable to store imported event")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		teamNameMap, driverNameMap, err := buildNameMaps(repo)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to generate name maps")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		branding, err := buildBrandingMaps(repo)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to generate branding maps")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, convertEventToResponse(*imported.Event, teamNameMap, driverNameMap, branding))
+	}
+}
+
+// loadCSVImport parses the uploaded file and builds the updated event. Invalid lines are
+// answered with 422 and all line errors. The request is aborted if ok is false.
+func loadCSVImport(ctx *gin.Context, repo jsondb.JsonDatabase) (*importedSession, bool) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportRequestSize)
+
+	eventID, err := strconv.Atoi(ctx.Param("race_id"))
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusBadRequest)
+		return nil, false
+	}
+
+	target, err := repo.GetEvent(uint64(eventID))
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusNotFound)
+		return nil, false
+	}
+
+	files, ok := readImportFiles(ctx)
+	if !ok {
+		return nil, false
+	}
+	if len(files) != 1 {
+		ctx.AbortWithStatus(http.StatusBadRequest)
+		return nil, false
+	}
+
+	teams, season, matcher, err := loadImportContext(repo, target.SeasonID)
+	if err != nil {
+		logrus.WithError(err).Warn("unable to load import context")
+		ctx.AbortWithStatus(http.StatusBadRequest)
+		return nil, false
+	}
+
+	_, driverNameMap, err := buildNameMaps(repo)
+	if err != nil {
+		logrus.WithError(err).Warn("unable to generate name maps")
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return nil, false
+	}
+
+	rows, warnings, lineErrors := parseCSVResults(files[0], matcher, driverNameMap)
+	if len(lineErrors) > 0 {
+		ctx.JSON(http.StatusUnprocessableEntity, csvImportErrorsResponse{Errors: lineErrors})
+		return nil, false
+	}
+
+	event, err := buildCSVEvent(rows, target, teams, season)
+	if err != nil {
+		logrus.WithError(err).Warn("unable to import csv results")
+		ctx.AbortWithStatus(http.StatusBadRequest)
+		return nil, false
+	}
+
+	return &importedSession{
+		Session:  target.Name,
+		Event:    event,
+		Existing: target,
+		Warnings: warnings,
+	}, true
+}
+
+// parseCSVResults validates every line of a classification CSV. Comma, semicolon and tab
+// separated files are accepted. The returned rows are sorted by position.
+func parseCSVResults(data []byte, matcher *driverMatcher, driverNameMap map[uint64]string) ([]csvResultRow, []string, []CSVLineError) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = detectCSVSeparator(data)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, []CSVLineError{{Line: 1, Message: "missing header line"}}
+	}
+
+	columns, err := mapCSVResultColumns(header)
+	if err != nil {
+		return nil, nil, []CSVLineError{{Line: 1, Message: err.Error()}}
+	}
+
+	rows := make([]csvResultRow, 0)
+	warnings := make([]string, 0)
+	lineErrors := make([]CSVLineError, 0)
+	seenDrivers := make(map[uint64]int)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			line := 0
+			if parseErr, ok := err.(*csv.ParseError); ok {
+				line = parseErr.Line
+			}
+			lineErrors = append(lineErrors, CSVLineError{Line: line, Message: err.Error()})
+			break
+		}
+
+		line, _ := reader.FieldPos(0)
+		if isEmptyCSVRecord(record) {
+			continue
+		}
+
+		row, warning, err := parseCSVResultRow(record, columns, matcher, driverNameMap)
+		if err != nil {
+			lineErrors = append(lineErrors, CSVLineError{Line: line, Message: err.Error()})
+			continue
+		}
+		row.Line = line
+		if warning != "" {
+			warnings = append(warnings, fmt.Sprintf("line %d: %s", line, warning))
+		}
+
+		if firstLine, ok := seenDrivers[row.DriverID]; ok {
+			lineErrors = append(lineErrors, CSVLineError{
+				Line:    line,
+				Message: fmt.Sprintf("%s is already classified in line %d", driverNameMap[row.DriverID], firstLine),
+			})
+			continue
+		}
+		seenDrivers[row.DriverID] = line
+
+		rows = append(rows, row)
+	}
+
+	if len(rows) <= 0 && len(lineErrors) <= 0 {
+		lineErrors = append(lineErrors, CSVLineError{Line: 1, Message: "file contains no results"})
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].Position < rows[j].Position
+	})
+	// gaps between positions can only be told apart from invalid lines once all lines are valid
+	if len(lineErrors) <= 0 {
+		lineErrors = validateCSVResultRows(rows, columns)
+	}
+
+	return rows, warnings, lineErrors
+}
+
+// validateCSVResultRows checks the rows sorted by position against each other. Positions and grid positions
+// must run from 1 without gaps and only one driver can have the fastest lap.
+func validateCSVResultRows(rows []csvResultRow, columns csvResultColumns) []CSVLineError {
+	lineErrors := make([]CSVLineError, 0)
+
+	next := uint64(1)
+	for index, row := range rows {
+		if index > 0 && row.Position == rows[index-1].Position {
+			lineErrors = append(lineErrors, CSVLineError{
+				Line:    row.Line,
+				Message: fmt.Sprintf("position %d is already taken in line %d", row.Position, rows[index-1].Line),
+			})
+			continue
+		}
+		if row.Position != next {
+			lineErrors = append(lineErrors, CSVLineError{
+				Line:    row.Line,
+				Message: fmt.Sprintf("position %d follows position %d", row.Position, next-1),
+			})
+		}
+		next = row.Position + 1
+	}
+
+	fastestLapLine := 0
+	for _, row := range rows {
+		if !row.FastestLap {
+			continue
+		}
+		if fastestLapLine > 0 {
+			lineErrors = append(lineErrors, CSVLineError{
+				Line:    row.Line,
+				Message: fmt.Sprintf("fastest lap is already set in line %d", fastestLapLine),
+			})
+			continue
+		}
+		fastestLapLine = row.Line
+	}
+
+	if columns.Grid >= 0 {
+		gridLines := make(map[uint64]int)
+		for _, row := range rows {
+			if row.Grid == 0 {
+				continue
+			}
+			if firstLine, ok := gridLines[row.Grid]; ok {
+				lineErrors = append(lineErrors, CSVLineError{
+					Line:    row.Line,
+					Message: fmt.Sprintf("grid position %d is already taken in line %d", row.Grid, firstLine),
+				})
+				continue
+			}
+			gridLines[row.Grid] = row.Line
+		}
+		for _, row := range rows {
+			if row.Grid > uint64(len(gridLines)) {
+				lineErrors = append(lineErrors, CSVLineError{
+					Line:    row.Line,
+					Message: fmt.Sprintf("grid position %d leaves a gap in the grid", row.Grid),
+				})
+			}
+		}
+	}
+
+	return lineErrors
+}
+
+func parseCSVResultRow(
+	record []string,
+	columns csvResultColumns,
+	matcher *driverMatcher,
+	driverNameMap map[uint64]string,
+) (csvResultRow, string, error) {
+	row := csvResultRow{}
+
+	position, err := strconv.ParseUint(csvField(record, columns.Position), 10, 64)
+	if err != nil || position == 0 {
+		return row, "", fmt.Errorf("invalid position %q", csvField(record, columns.Position))
+	}
+	row.Position = position
+
+	if gridValue := csvField(record, columns.Grid); gridValue != "" {
+		grid, err := strconv.ParseUint(gridValue, 10, 64)
+		if err != nil || grid == 0 {
+			return row, "", fmt.Errorf("invalid grid position %q", gridValue)
+		}
+		row.Grid = grid
+	}
+
+	driverValue := csvField(record, columns.Driver)
+	driverID, fuzzy, err := matchCSVDriver(driverValue, matcher)
+	if err != nil {
+		return row, "", err
+	}
+	row.DriverID = driverID
+
+	warning := ""
+	if fuzzy {
+		warning = fmt.Sprintf("%q matched to %s", driverValue, driverNameMap[driverID])
+	}
+
+	status, err := parseCSVResultStatus(csvField(record, columns.Status))
+	if err != nil {
+		return row, "", err
+	}
+	row.Status = status
+
+	fastestLap, err := parseCSVFlag(csvField(record, columns.FastestLap))
+	if err != nil {
+		return row, "", fmt.Errorf("fastest lap: %w", err)
+	}
+	row.FastestLap = fastestLap
+
+	if lapsValue := csvField(record, columns.Laps); lapsValue != "" {
+		laps, err := strconv.ParseUint(lapsValue, 10, 64)
+		if err != nil {
+			return row, "", fmt.Errorf("invalid laps %q", lapsValue)
+		}
+		row.Laps = laps
+	}
+
+	if bestLapValue := csvField(record, columns.BestLap); bestLapValue != "" {
+		bestLapMS, err := parseLapTime(bestLapValue)
+		if err != nil {
+			return row, "", err
+		}
+		row.BestLapMS = bestLapMS
+	}
+
+	return row, warning, nil
+}
+
+// matchCSVDriver accepts driver IDs, names and name aliases. Anything else is matched
+// fuzzy and reported with fuzzy set to true.
+func matchCSVDriver(value string, matcher *driverMatcher) (driverID uint64, fuzzy bool, err error) {
+	if value == "" {
+		return 0, false, fmt.Errorf("missing driver")
+	}
+
+	if id, err := strconv.ParseUint(value, 10, 64); err == nil {
+		if _, ok := matcher.fuzzyNames[id]; !ok {
+			return 0, false, fmt.Errorf("unknown driver ID %d", id)
+		}
+		return id, false, nil
+	}
+
+	if driverID, ok := matcher.matchName(value); ok {
+		return driverID, false, nil
+	}
+
+	if driverID, ok := matcher.matchFuzzy(value); ok {
+		return driverID, true, nil
+	}
+
+	return 0, false, fmt.Errorf("no unique driver found for %q", value)
+}
+
+func parseCSVResultStatus(value string) (jsondb.ResultStatus, error) {
+	switch strings.ToLower(value) {
+	case "", "finished", "classified", "running":
+		return jsondb.FinishedResultStatus, nil
+	case "dnf", "ret", "retired":
+		return jsondb.DNFResultStatus, nil
+	case "dsq", "dq", "disqualified":
+		return jsondb.DSQResultStatus, nil
+	case "dns":
+		return jsondb.DNSResultStatus, nil
+	default:
+		return 0, fmt.Errorf("unknown status %q", value)
+	}
+}
+
+func parseCSVFlag(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "", "0", "no", "n", "false":
+		return false, nil
+	case "1", "yes", "y", "true", "x":
+		return true, nil
+	default:
+		return false, fmt.Errorf("%q is neither yes nor no", value)
+	}
+}
+
+// parseLapTime reads lap times as m:ss.SSS or as seconds.
+func parseLapTime(value string) (uint64, error) {
+	minutes := 0.0
+	secondsPart := value
+	if index := strings.Index(value, ":"); index >= 0 {
+		m, err := strconv.ParseUint(value[:index], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid lap time %q", value)
+		}
+		minutes = float64(m)
+		secondsPart = value[index+1:]
+	}
+
+	seconds, err := strconv.ParseFloat(strings.Replace(secondsPart, ",", ".", 1), 64)
+	if err != nil || seconds < 0 {
+		return 0, fmt.Errorf("invalid lap time %q", value)
+	}
+
+	return uint64((minutes*60+seconds)*1000 + 0.5), nil
+}
+
+func mapCSVResultColumns(header []string) (csvResultColumns, error) {
+	indexes := make(map[string]int)
+	for index, name := range header {
+		name = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "_")
+		for column, names := range csvColumnNames {
+			for _, accepted := range names {
+				if name != accepted {
+					continue
+				}
+				if _, ok := indexes[column]; ok {
+					return csvResultColumns{}, fmt.Errorf("column %s is given more than once", column)
+				}
+				indexes[column] = index
+			}
+		}
+	}
+
+	for _, required := range []string{"position", "driver"} {
+		if _, ok := indexes[required]; !ok {
+			return csvResultColumns{}, fmt.Errorf("missing %s column", required)
+		}
+	}
+
+	column := func(name string) int {
+		if index, ok := indexes[name]; ok {
+			return index
+		}
+		return -1
+	}
+
+	return csvResultColumns{
+		Position:   column("position"),
+		Grid:       column("grid"),
+		Driver:     column("driver"),
+		Status:     column("status"),
+		FastestLap: column("fastest_lap"),
+		Laps:       column("laps"),
+		BestLap:    column("best_lap"),
+	}, nil
+}
+
+// detectCSVSeparator picks the separator used most in the header line. Spreadsheets in many
+// locales export semicolon separated files.
+func detectCSVSeparator(data []byte) rune {
+	headerLine := data
+	if index := bytes.IndexByte(data, '\n'); index >= 0 {
+		headerLine = data[:index]
+	}
+
+	separator := ','
+	count := bytes.Count(headerLine, []byte{','})
+	for _, candidate := range []rune{';', '\t'} {
+		if candidateCount := bytes.Count(headerLine, []byte(string(candidate))); candidateCount > count {
+			separator = candidate
+			count = candidateCount
+		}
+	}
+
+	return separator
+}
+
+func csvField(record []string, index int) string {
+	if index < 0 || index >= len(record) {
+		return ""
+	}
+
+	return strings.TrimSpace(record[index])
+}
+
+func isEmptyCSVRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// buildCSVEvent replaces the results of the target event. Without a grid column the starting
+// grid of the event is kept. Completed events stay completed, all others become drafts.
+func buildCSVEvent(rows []csvResultRow, target *jsondb.RaceEvent, teams []jsondb.Team, season *jsondb.Season) (*jsondb.RaceEvent, error) {
+	userInput := newTargetEventRequest(target)
+
+	bestLaps := make(map[uint64]uint64)
+	gridRows := make([]csvResultRow, 0)
+	for _, row := range rows {
+		userInput.Results = append(userInput.Results, row.DriverID)
+		switch row.Status {
+		case jsondb.DNFResultStatus:
+			userInput.DNF = append(userInput.DNF, row.DriverID)
+		case jsondb.DSQResultStatus:
+			userInput.DSQ = append(userInput.DSQ, row.DriverID)
+		}
+		if row.FastestLap {
+			driverID := row.DriverID
+			userInput.FastestLap = &driverID
+		}
+		if row.Laps > 0 {
+			userInput.Strategies = append(userInput.Strategies, driverStrategyRequest{DriverID: row.DriverID, Laps: row.Laps})
+		}
+		if row.BestLapMS > 0 {
+			bestLaps[row.DriverID] = row.BestLapMS
+		}
+		if row.Grid > 0 {
+			gridRows = append(gridRows, row)
+		}
+	}
+
+	if len(gridRows) > 0 {
+		sort.Slice(gridRows, func(i, j int) bool {
+			return gridRows[i].Grid < gridRows[j].Grid
+		})
+		for _, row := range gridRows {
+			userInput.StartingGrid = append(userInput.StartingGrid, row.DriverID)
+		}
+	} else {
+		for _, grid := range target.StartingGrid {
+			userInput.StartingGrid = append(userInput.StartingGrid, grid.DriverID)
+		}
+	}
+
+	events, err := buildImportedEvents([]*raceEventRequest{userInput}, []map[uint64]uint64{bestLaps}, teams, season)
+	if err != nil {
+		return nil, err
+	}
+
+	event := events[0]
+	event.ID = target.ID
+	event.ExternalID = target.ExternalID
+	if len(gridRows) <= 0 {
+		event.GridGeneration = target.GridGeneration
+	}
+
+	for _, row := range rows {
+		if row.Status != jsondb.DNSResultStatus {
+			continue
+		}
+		for index := range event.Results {
+			res := &event.Results[index]
+			if res.DriverID == row.DriverID {
+				res.Status = jsondb.DNSResultStatus
+				res.Points = 0
+				res.ClassPoints = 0
+				res.FastestLap = false
+			}
+		}
+	}
+
+	return event, nil
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+)
+
+// diffEvents lists what an import would change on an existing event. Positions are compared
+// per driver so a driver moving up shows as a single change.
+func diffEvents(existing, updated jsondb.RaceEvent, driverNameMap map[uint64]string) eventDiffResponse {
+	diff := eventDiffResponse{
+		Fields:       make([]fieldChangeResponse, 0),
+		StartingGrid: diffPositions(existing.StartingGrid, updated.StartingGrid, driverNameMap),
+		Results:      diffPositions(existing.Results, updated.Results, driverNameMap),
+	}
+
+	fields := []fieldChangeResponse{
+		{Field: "name", Old: existing.Name, New: updated.Name},
+		{Field: "date", Old: formatDiffDate(existing.Date), New: formatDiffDate(updated.Date)},
+		{Field: "type", Old: existing.Type.Name(), New: updated.Type.Name()},
+		{Field: "status", Old: existing.Status.Name(), New: updated.Status.Name()},
+		{Field: "season_id", Old: strconv.FormatUint(existing.SeasonID, 10), New: strconv.FormatUint(updated.SeasonID, 10)},
+	}
+	for _, field := range fields {
+		if field.Old != field.New {
+			diff.Fields = append(diff.Fields, field)
+		}
+	}
+
+	return diff
+}
+
+func diffPositions(existing, updated []jsondb.RacePosition, driverNameMap map[uint64]string) []positionChangeResponse {
+	changes := make([]positionChangeResponse, 0)
+	for _, pos := range updated {
+		newSnapshot := snapshotPosition(pos)
+		old, ok := findPosition(existing, pos.DriverID)
+		if !ok {
+			changes = append(changes, positionChangeResponse{
+				DriverID:   pos.DriverID,
+				DriverName: driverNameMap[pos.DriverID],
+				Change:     "added",
+				New:        &newSnapshot,
+			})
+			continue
+		}
+
+		oldSnapshot := snapshotPosition(old)
+		if oldSnapshot != newSnapshot {
+			changes = append(changes, positionChangeResponse{
+				DriverID:   pos.DriverID,
+				DriverName: driverNameMap[pos.DriverID],
+				Change:     "changed",
+				Old:        &oldSnapshot,
+				New:        &newSnapshot,
+			})
+		}
+	}
+
+	for _, pos := range existing {
+		if _, ok := findPosition(updated, pos.DriverID); !ok {
+			oldSnapshot := snapshotPosition(pos)
+			changes = append(changes, positionChangeResponse{
+				DriverID:   pos.DriverID,
+				DriverName: driverNameMap[pos.DriverID],
+				Change:     "removed",
+				Old:        &oldSnapshot,
+			})
+		}
+	}
+
+	return changes
+}
+
+func snapshotPosition(pos jsondb.RacePosition) positionSnapshotResponse {
+	return positionSnapshotResponse{
+		Position:      pos.Position,
+		Points:        pos.Points,
+		TeamID:        pos.TeamID,
+		Status:        pos.Status.Name(),
+		FastestLap:    pos.FastestLap,
+		BestLapMS:     pos.BestLapMS,
+		ClassID:       pos.ClassID,
+		ClassPosition: pos.ClassPosition,
+		Laps:          pos.Laps,
+		PitStops:      len(pos.PitStops),
+	}
+}
+
+func formatDiffDate(unix int64) string {
+	return time.Unix(unix, 0).UTC().Format(time.RFC3339)
+}
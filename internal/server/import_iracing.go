@@ -0,0 +1,287 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+	"github.com/devnull-twitch/nyooom-backend/pkg/results/iracing"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// ImportIRacingResultsHandler stores the qualifying and race simsessions of exported iRacing
+// results as events. Sessions that were imported before update their event, so importing
+// the same file again changes nothing.
+func ImportIRacingResultsHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportRequestSize)
+
+		options, ok := bindImportOptions(ctx)
+		if !ok {
+			return
+		}
+
+		files, ok := readImportFiles(ctx)
+		if !ok {
+			return
+		}
+
+		results := make([]*iracing.Results, 0, len(files))
+		for _, file := range files {
+			result, err := iracing.Parse(file)
+			if err != nil {
+				logrus.WithError(err).Warn("unable to parse iracing results file")
+				ctx.AbortWithStatus(http.StatusBadRequest)
+				return
+			}
+			results = append(results, result)
+		}
+
+		events, err := repo.ListEvents()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read events")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		// updated events stay in their season
+		for _, result := range results {
+			for _, session := range result.SessionResults {
+				if existing, ok := findExternalEvent(events, iracingExternalID(result, session)); ok {
+					options.SeasonID = existing.SeasonID
+				}
+			}
+		}
+
+		teams, season, matcher, err := loadImportContext(repo, options.SeasonID)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to load import context")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		imports, unmatched, err := buildIRacingImports(results, events, teams, season, matcher, options)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to import iracing results")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		if len(unmatched) > 0 {
+			ctx.JSON(http.StatusUnprocessableEntity, unmatchedDriversResponse{Unmatched: unmatched})
+			return
+		}
+
+		stored := make([]*jsondb.RaceEvent, 0, len(imports))
+		for _, imported := range imports {
+			if imported.Existing != nil {
+				err = repo.UpdateEvent(imported.Event)
+			} else {
+				err = repo.AddEvent(imported.Event)
+			}
+			if err != nil {
+				logrus.WithError(err).Warn("unable to store imported event")
+				ctx.AbortWithStatus(http.StatusInternalServerError)
+				return
+			}
+			stored = append(stored, imported.Event)
+		}
+
+		respondImportedEvents(ctx, repo, stored)
+	}
+}
+
+func buildIRacingImports(
+	results []*iracing.Results,
+	events []jsondb.RaceEvent,
+	teams []jsondb.Team,
+	season *jsondb.Season,
+	matcher *driverMatcher,
+	options ImportOptions,
+) ([]importedSession, []UnmatchedDriver, error) {
+	imports := make([]importedSession, 0)
+	requests := make([]*raceEventRequest, 0)
+	bestLaps := make([]map[uint64]uint64, 0)
+	incidents := make([]map[uint64]uint64, 0)
+	externalIDs := make([]string, 0)
+	unmatched := make([]UnmatchedDriver, 0)
+	for _, result := range results {
+		for _, session := range result.SessionResults {
+			var eventType jsondb.EventType
+			switch session.Kind() {
+			case iracing.RaceSessionKind:
+				eventType = jsondb.RaceEventType
+			case iracing.QualifyingSessionKind:
+				eventType = jsondb.QualifyingEventType
+			default:
+				continue
+			}
+
+			externalID := iracingExternalID(result, session)
+			imported := importedSession{
+				Session:  fmt.Sprintf("%s %s", result.Track.TrackName, session.SimsessionName),
+				Warnings: make([]string, 0),
+			}
+
+			var userInput *raceEventRequest
+			if existing, ok := findExternalEvent(events, externalID); ok {
+				imported.Existing = &existing
+				userInput = newTargetEventRequest(&existing)
+				// an event confirmed by an editor stays confirmed
+				status := existing.Status
+				userInput.Status = &status
+			} else {
+				sessionOptions := options
+				if sessionOptions.Date == 0 {
+					sessionOptions.Date = result.StartUnix()
+				}
+				userInput = newImportEventRequest(result.Track.TrackName, eventType, sessionOptions)
+			}
+
+			sessionBestLaps, sessionIncidents, sessionUnmatched, err := applyIRacingSession(userInput, session, imported.Session, season, matcher)
+			if err != nil {
+				return nil, nil, err
+			}
+			unmatched = append(unmatched, sessionUnmatched...)
+
+			imports = append(imports, imported)
+			requests = append(requests, userInput)
+			bestLaps = append(bestLaps, sessionBestLaps)
+			incidents = append(incidents, sessionIncidents)
+			externalIDs = append(externalIDs, externalID)
+		}
+	}
+
+	if len(imports) <= 0 {
+		return nil, nil, fmt.Errorf("no qualifying or race session to import")
+	}
+	if len(unmatched) > 0 {
+		return nil, unmatched, nil
+	}
+
+	built, err := buildImportedEvents(requests, bestLaps, teams, season)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for index, event := range built {
+		for resIndex := range event.Results {
+			event.Results[resIndex].IncidentPoints = incidents[index][event.Results[resIndex].DriverID]
+		}
+
+		event.ExternalID = externalIDs[index]
+		if imports[index].Existing != nil {
+			event.ID = imports[index].Existing.ID
+		}
+		imports[index].Event = event
+	}
+
+	return imports, nil, nil
+}
+
+// applyIRacingSession fills the event request with the results of the simsession and returns
+// the best laps and incidents per driver.
+func applyIRacingSession(
+	userInput *raceEventRequest,
+	session iracing.Session,
+	sessionName string,
+	season *jsondb.Season,
+	matcher *driverMatcher,
+) (map[uint64]uint64, map[uint64]uint64, []UnmatchedDriver, error) {
+	carResults := make([]iracing.Result, len(session.Results))
+	copy(carResults, session.Results)
+	sort.SliceStable(carResults, func(i, j int) bool {
+		return carResults[i].FinishPosition < carResults[j].FinishPosition
+	})
+
+	bestLaps := make(map[uint64]uint64)
+	incidents := make(map[uint64]uint64)
+	unmatched := make([]UnmatchedDriver, 0)
+	gridPositions := make(map[uint64]int)
+	var fastestLapMS uint64
+	for _, carResult := range carResults {
+		driver := carResult.Driver()
+
+		driverID, ok := matcher.matchAlias(jsondb.IRacingAliasType, strconv.FormatUint(driver.CustID, 10))
+		if !ok {
+			driverID, ok = matcher.matchName(driver.DisplayName)
+		}
+		if !ok {
+			carNumber, _ := strconv.ParseUint(carResult.Livery.CarNumber, 10, 64)
+			unmatched = append(unmatched, UnmatchedDriver{
+				Session:   sessionName,
+				CarNumber: carNumber,
+				Name:      driver.DisplayName,
+				PlayerID:  strconv.FormatUint(driver.CustID, 10),
+			})
+			continue
+		}
+		if IDisInList(userInput.Results, driverID) {
+			return nil, nil, nil, fmt.Errorf("driver %d matched multiple cars in %s", driverID, sessionName)
+		}
+
+		userInput.Results = append(userInput.Results, driverID)
+		if carResult.Disqualified() {
+			userInput.DSQ = append(userInput.DSQ, driverID)
+		} else if !carResult.Running() && userInput.Type != jsondb.QualifyingEventType {
+			userInput.DNF = append(userInput.DNF, driverID)
+		}
+
+		if carResult.StartingPosition >= 0 && userInput.Type != jsondb.QualifyingEventType {
+			gridPositions[driverID] = carResult.StartingPosition
+		}
+
+		if carResult.Incidents > 0 {
+			incidents[driverID] = uint64(carResult.Incidents)
+		}
+
+		if bestLap := carResult.BestLapMS(); bestLap > 0 {
+			bestLaps[driverID] = bestLap
+			if userInput.Type != jsondb.QualifyingEventType && (fastestLapMS == 0 || bestLap < fastestLapMS) {
+				fastestLapMS = bestLap
+				fastestDriverID := driverID
+				userInput.FastestLap = &fastestDriverID
+			}
+		}
+
+		laps := uint64(0)
+		if carResult.LapsComplete > 0 {
+			laps = uint64(carResult.LapsComplete)
+		}
+		userInput.Strategies = append(userInput.Strategies, driverStrategyRequest{DriverID: driverID, Laps: laps})
+
+		if classID, ok := findClassByName(season, carResult.CarClassName); ok {
+			userInput.ClassEntries = setClassEntry(userInput.ClassEntries, driverID, classID)
+		} else if classID, ok := findClassByName(season, carResult.CarClassShortName); ok {
+			userInput.ClassEntries = setClassEntry(userInput.ClassEntries, driverID, classID)
+		}
+	}
+
+	for _, driverID := range userInput.Results {
+		if _, ok := gridPositions[driverID]; ok {
+			userInput.StartingGrid = append(userInput.StartingGrid, driverID)
+		}
+	}
+	sort.SliceStable(userInput.StartingGrid, func(i, j int) bool {
+		return gridPositions[userInput.StartingGrid[i]] < gridPositions[userInput.StartingGrid[j]]
+	})
+
+	return bestLaps, incidents, unmatched, nil
+}
+
+// iracingExternalID identifies a simsession by its subsession ID and simsession number.
+func iracingExternalID(result *iracing.Results, session iracing.Session) string {
+	return fmt.Sprintf("iracing:%d:%d", result.SubsessionID, session.SimsessionNumber)
+}
+
+func findExternalEvent(events []jsondb.RaceEvent, externalID string) (jsondb.RaceEvent, bool) {
+	for _, e := range events {
+		if e.ExternalID == externalID {
+			return e, true
+		}
+	}
+
+	return jsondb.RaceEvent{}, false
+}
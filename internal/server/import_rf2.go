@@ -0,0 +1,363 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+	"github.com/devnull-twitch/nyooom-backend/pkg/results/rf2"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// PreviewRF2ResultsHandler shows the events an rF2 results upload would create or update
+// without saving anything. Updates include a diff against the stored event.
+func PreviewRF2ResultsHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		imports, unmatched, ok := loadRF2Imports(ctx, repo)
+		if !ok {
+			return
+		}
+
+		teamNameMap, driverNameMap, err := buildNameMaps(repo)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to generate name maps")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		branding, err := buildBrandingMaps(repo)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to generate branding maps")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		resp := importPreviewResponse{
+			Sessions:  make([]importPreviewSessionResponse, 0, len(imports)),
+			Unmatched: unmatched,
+		}
+		for _, imported := range imports {
+			session := importPreviewSessionResponse{
+				Session:  imported.Session,
+				Action:   "create",
+				Event:    convertEventToResponse(*imported.Event, teamNameMap, driverNameMap, branding),
+				Warnings: imported.Warnings,
+			}
+			if imported.Existing != nil {
+				changes := diffEvents(*imported.Existing, *imported.Event, driverNameMap)
+				session.Action = "update"
+				session.Changes = &changes
+			}
+			resp.Sessions = append(resp.Sessions, session)
+		}
+
+		ctx.JSON(http.StatusOK, resp)
+	}
+}
+
+// ImportRF2ResultsHandler stores the sessions of rF2 results files as draft events. With an
+// event_id the results of the single session replace the results of that event.
+func ImportRF2ResultsHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		imports, unmatched, ok := loadRF2Imports(ctx, repo)
+		if !ok {
+			return
+		}
+		if len(unmatched) > 0 {
+			ctx.JSON(http.StatusUnprocessableEntity, unmatchedDriversResponse{Unmatched: unmatched})
+			return
+		}
+
+		events := make([]*jsondb.RaceEvent, 0, len(imports))
+		for _, imported := range imports {
+			var err error
+			if imported.Existing != nil {
+				err = repo.UpdateEvent(imported.Event)
+			} else {
+				err = repo.AddEvent(imported.Event)
+			}
+			if err != nil {
+				logrus.WithError(err).Warn("unable to store imported event")
+				ctx.AbortWithStatus(http.StatusInternalServerError)
+				return
+			}
+			events = append(events, imported.Event)
+		}
+
+		respondImportedEvents(ctx, repo, events)
+	}
+}
+
+// loadRF2Imports parses the uploaded files and builds their events. The request is aborted
+// if ok is false.
+func loadRF2Imports(ctx *gin.Context, repo jsondb.JsonDatabase) (imports []importedSession, unmatched []UnmatchedDriver, ok bool) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportRequestSize)
+
+	options, ok := bindImportOptions(ctx)
+	if !ok {
+		return nil, nil, false
+	}
+
+	var target *jsondb.RaceEvent
+	if eventParam := ctx.PostForm("event_id"); eventParam != "" {
+		eventID, err := strconv.Atoi(eventParam)
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return nil, nil, false
+		}
+
+		target, err = repo.GetEvent(uint64(eventID))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return nil, nil, false
+		}
+		options.SeasonID = target.SeasonID
+	}
+
+	files, ok := readImportFiles(ctx)
+	if !ok {
+		return nil, nil, false
+	}
+
+	results := make([]*rf2.Results, 0, len(files))
+	for _, file := range files {
+		result, err := rf2.Parse(file)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to parse rf2 results file")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return nil, nil, false
+		}
+		results = append(results, result)
+	}
+
+	teams, season, matcher, err := loadImportContext(repo, options.SeasonID)
+	if err != nil {
+		logrus.WithError(err).Warn("unable to load import context")
+		ctx.AbortWithStatus(http.StatusBadRequest)
+		return nil, nil, false
+	}
+
+	imports, unmatched, err = buildRF2Imports(results, target, teams, season, matcher, options)
+	if err != nil {
+		logrus.WithError(err).Warn("unable to import rf2 results")
+		ctx.AbortWithStatus(http.StatusBadRequest)
+		return nil, nil, false
+	}
+
+	return imports, unmatched, true
+}
+
+// buildRF2Imports turns every qualifying and race session into an event. Practice and warmup
+// sessions are skipped. Drivers that can not be matched are left out of the events.
+func buildRF2Imports(
+	results []*rf2.Results,
+	target *jsondb.RaceEvent,
+	teams []jsondb.Team,
+	season *jsondb.Season,
+	matcher *driverMatcher,
+	options ImportOptions,
+) ([]importedSession, []UnmatchedDriver, error) {
+	imports := make([]importedSession, 0)
+	requests := make([]*raceEventRequest, 0)
+	bestLaps := make([]map[uint64]uint64, 0)
+	unmatched := make([]UnmatchedDriver, 0)
+	for _, result := range results {
+		for _, session := range result.Sessions {
+			var eventType jsondb.EventType
+			switch session.Kind() {
+			case rf2.RaceSessionKind:
+				eventType = jsondb.RaceEventType
+			case rf2.QualifyingSessionKind:
+				eventType = jsondb.QualifyingEventType
+			default:
+				continue
+			}
+
+			imported := importedSession{
+				Session:  fmt.Sprintf("%s %s", result.TrackVenue, session.Name()),
+				Existing: target,
+				Warnings: make([]string, 0),
+			}
+
+			var userInput *raceEventRequest
+			if target != nil {
+				// a qualifying session must not replace the results of a race and the other way around
+				if (target.Type == jsondb.QualifyingEventType) != (eventType == jsondb.QualifyingEventType) {
+					return nil, nil, fmt.Errorf("%s can not update event %d of type %s", imported.Session, target.ID, target.Type.Name())
+				}
+				userInput = newTargetEventRequest(target)
+			} else {
+				sessionOptions := options
+				if sessionOptions.Date == 0 {
+					sessionOptions.Date = session.DateTime
+				}
+				userInput = newImportEventRequest(result.TrackVenue, eventType, sessionOptions)
+			}
+
+			sessionBestLaps, sessionUnmatched, warnings, err := applyRF2Session(userInput, session, imported.Session, season, matcher)
+			if err != nil {
+				return nil, nil, err
+			}
+			unmatched = append(unmatched, sessionUnmatched...)
+			imported.Warnings = append(imported.Warnings, warnings...)
+
+			imports = append(imports, imported)
+			requests = append(requests, userInput)
+			bestLaps = append(bestLaps, sessionBestLaps)
+		}
+	}
+
+	if len(imports) <= 0 {
+		return nil, nil, fmt.Errorf("no qualifying or race session to import")
+	}
+	if target != nil && len(imports) > 1 {
+		return nil, nil, fmt.Errorf("only a single session can update event %d", target.ID)
+	}
+
+	events, err := buildImportedEvents(requests, bestLaps, teams, season)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for index, event := range events {
+		if target != nil {
+			event.ID = target.ID
+		}
+		imports[index].Event = event
+	}
+
+	return imports, unmatched, nil
+}
+
+// applyRF2Session fills the event request with the classification of the session.
+// Drivers with the None finish status did not take part.
+func applyRF2Session(
+	userInput *raceEventRequest,
+	session rf2.Session,
+	sessionName string,
+	season *jsondb.Season,
+	matcher *driverMatcher,
+) (map[uint64]uint64, []UnmatchedDriver, []string, error) {
+	drivers := make([]rf2.Driver, len(session.Drivers))
+	copy(drivers, session.Drivers)
+	sort.SliceStable(drivers, func(i, j int) bool {
+		return drivers[i].Position < drivers[j].Position
+	})
+
+	bestLaps := make(map[uint64]uint64)
+	unmatched := make([]UnmatchedDriver, 0)
+	warnings := make([]string, 0)
+	gridPositions := make(map[uint64]uint64)
+	classNames := make(map[string]bool)
+	var fastestLapMS uint64
+	for _, driver := range drivers {
+		if driver.FinishStatus == rf2.NoneFinishStatus {
+			continue
+		}
+
+		driverID, ok := matcher.matchName(driver.Name)
+		if !ok {
+			carNumber, _ := strconv.ParseUint(driver.CarNumber, 10, 64)
+			unmatched = append(unmatched, UnmatchedDriver{
+				Session:   sessionName,
+				CarNumber: carNumber,
+				Name:      driver.Name,
+			})
+			continue
+		}
+		if IDisInList(userInput.Results, driverID) {
+			return nil, nil, nil, fmt.Errorf("driver %d matched multiple cars in %s", driverID, sessionName)
+		}
+
+		userInput.Results = append(userInput.Results, driverID)
+		switch driver.FinishStatus {
+		case rf2.DNFFinishStatus:
+			userInput.DNF = append(userInput.DNF, driverID)
+		case rf2.DQFinishStatus:
+			userInput.DSQ = append(userInput.DSQ, driverID)
+		}
+
+		if driver.GridPos > 0 {
+			gridPositions[driverID] = driver.GridPos
+		}
+
+		if bestLap := driver.BestLapMS(); bestLap > 0 {
+			bestLaps[driverID] = bestLap
+			if userInput.Type != jsondb.QualifyingEventType && (fastestLapMS == 0 || bestLap < fastestLapMS) {
+				fastestLapMS = bestLap
+				fastestDriverID := driverID
+				userInput.FastestLap = &fastestDriverID
+			}
+		}
+
+		userInput.Strategies = append(userInput.Strategies, convertRF2Strategy(driverID, driver))
+
+		if driver.CarClass != "" {
+			classNames[driver.CarClass] = true
+			if classID, ok := findClassByName(season, driver.CarClass); ok {
+				userInput.ClassEntries = setClassEntry(userInput.ClassEntries, driverID, classID)
+			}
+		}
+	}
+
+	for _, driverID := range userInput.Results {
+		if _, ok := gridPositions[driverID]; ok {
+			userInput.StartingGrid = append(userInput.StartingGrid, driverID)
+		}
+	}
+	sort.SliceStable(userInput.StartingGrid, func(i, j int) bool {
+		return gridPositions[userInput.StartingGrid[i]] < gridPositions[userInput.StartingGrid[j]]
+	})
+
+	// a single class field is not scored per class so it does not need a season class
+	if len(classNames) > 1 {
+		sortedNames := make([]string, 0, len(classNames))
+		for name := range classNames {
+			sortedNames = append(sortedNames, name)
+		}
+		sort.Strings(sortedNames)
+
+		for _, name := range sortedNames {
+			if _, ok := findClassByName(season, name); !ok {
+				warnings = append(warnings, fmt.Sprintf("car class %s is no class of the season and is scored without class", name))
+			}
+		}
+	}
+
+	return bestLaps, unmatched, warnings, nil
+}
+
+// convertRF2Strategy turns the laps the driver pitted on into pit stops. The compound of a
+// stop is the compound of the lap after it. rF2 does not record stationary times.
+func convertRF2Strategy(driverID uint64, driver rf2.Driver) driverStrategyRequest {
+	strategy := driverStrategyRequest{
+		DriverID: driverID,
+		Laps:     driver.Laps,
+	}
+
+	laps := make([]rf2.Lap, len(driver.LapList))
+	copy(laps, driver.LapList)
+	sort.SliceStable(laps, func(i, j int) bool {
+		return laps[i].Num < laps[j].Num
+	})
+
+	if len(laps) > 0 {
+		strategy.StartCompound = convertCompoundName(laps[0].CompoundName())
+	}
+	for index, lap := range laps {
+		if lap.Pit != 1 || (driver.Laps > 0 && lap.Num > driver.Laps) {
+			continue
+		}
+
+		stop := jsondb.PitStop{Lap: lap.Num}
+		if index+1 < len(laps) {
+			stop.Compound = convertCompoundName(laps[index+1].CompoundName())
+		}
+		strategy.PitStops = append(strategy.PitStops, stop)
+	}
+
+	return strategy
+}
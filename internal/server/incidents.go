@@ -0,0 +1,253 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// GetIncidentsHandler lists incidents filtered by the optional driver_id, race_id and season_id query parameters.
+func GetIncidentsHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		incidents, err := repo.ListIncidents()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read incidents")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		events, err := repo.ListEvents()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read events")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		season, ok := querySeason(ctx, repo)
+		if !ok {
+			return
+		}
+
+		eventIDs := make([]uint64, 0)
+		for _, e := range filterEvents(events, isInSeason(season)) {
+			eventIDs = append(eventIDs, e.ID)
+		}
+
+		if raceParam := ctx.Query("race_id"); raceParam != "" {
+			raceID, err := strconv.Atoi(raceParam)
+			if err != nil {
+				ctx.AbortWithStatus(http.StatusBadRequest)
+				return
+			}
+			incidents = filterIncidents(incidents, func(i jsondb.Incident) bool {
+				return i.EventID == uint64(raceID)
+			})
+		}
+
+		if driverParam := ctx.Query("driver_id"); driverParam != "" {
+			driverID, err := strconv.Atoi(driverParam)
+			if err != nil {
+				ctx.AbortWithStatus(http.StatusBadRequest)
+				return
+			}
+			incidents = filterIncidents(incidents, func(i jsondb.Incident) bool {
+				return IDisInList(i.DriverIDs, uint64(driverID))
+			})
+		}
+
+		incidents = filterIncidents(incidents, func(i jsondb.Incident) bool {
+			return IDisInList(eventIDs, i.EventID)
+		})
+
+		_, driverNameMap, err := buildNameMaps(repo)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to generate name maps")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, convertIncidentsToResponse(incidents, events, driverNameMap))
+	}
+}
+
+func GetIncidentHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		incidentID, err := strconv.Atoi(ctx.Param("incident_id"))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		incident, err := repo.GetIncident(uint64(incidentID))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		events, err := repo.ListEvents()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read events")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		_, driverNameMap, err := buildNameMaps(repo)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to generate name maps")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, convertIncidentsToResponse([]jsondb.Incident{*incident}, events, driverNameMap)[0])
+	}
+}
+
+func AddIncidentHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		newIncident := &jsondb.Incident{}
+
+		if err := ctx.BindJSON(newIncident); err != nil {
+			logrus.WithError(err).Warn("unable to get user input for new incident")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		if err := validateIncident(newIncident, repo); err != nil {
+			logrus.WithError(err).Warn("invalid incident")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		if err := repo.AddIncident(newIncident); err != nil {
+			logrus.WithError(err).Warn("unable to add incident")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, newIncident)
+	}
+}
+
+func UpdateIncidentHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userInputIncident := &jsondb.Incident{}
+
+		if err := ctx.BindJSON(userInputIncident); err != nil {
+			logrus.WithError(err).Warn("unable to get user input for incident update")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		incidentID, err := strconv.Atoi(ctx.Param("incident_id"))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		existing, err := repo.GetIncident(uint64(incidentID))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		if err := validateIncident(userInputIncident, repo); err != nil {
+			logrus.WithError(err).Warn("invalid incident")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		userInputIncident.ID = existing.ID
+		if err := repo.UpdateIncident(userInputIncident); err != nil {
+			logrus.WithError(err).Warn("unable to update incident")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		ctx.Status(http.StatusOK)
+	}
+}
+
+func DeleteIncidentHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		incidentID, err := strconv.Atoi(ctx.Param("incident_id"))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		if err := repo.DeleteIncident(uint64(incidentID)); err != nil {
+			logrus.WithError(err).Warn("unable to delete incident")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		ctx.Status(http.StatusOK)
+	}
+}
+
+func filterIncidents(incidents []jsondb.Incident, filter func(i jsondb.Incident) bool) []jsondb.Incident {
+	filtered := make([]jsondb.Incident, 0, len(incidents))
+	for _, i := range incidents {
+		if filter(i) {
+			filtered = append(filtered, i)
+		}
+	}
+
+	return filtered
+}
+
+func validateIncident(incident *jsondb.Incident, repo jsondb.JsonDatabase) error {
+	if _, err := repo.GetEvent(incident.EventID); err != nil {
+		return fmt.Errorf("unknown event %d", incident.EventID)
+	}
+
+	_, driverNameMap, err := buildNameMaps(repo)
+	if err != nil {
+		return err
+	}
+
+	if len(incident.DriverIDs) <= 0 {
+		return fmt.Errorf("incident needs at least one driver")
+	}
+	for index, driverID := range incident.DriverIDs {
+		if _, ok := driverNameMap[driverID]; !ok {
+			return fmt.Errorf("unknown driver %d", driverID)
+		}
+		if IDisInList(incident.DriverIDs[:index], driverID) {
+			return fmt.Errorf("driver %d is listed multiple times", driverID)
+		}
+	}
+
+	incident.Description = strings.TrimSpace(incident.Description)
+	if incident.Description == "" {
+		return fmt.Errorf("incident needs a description")
+	}
+
+	incident.VODURL = strings.TrimSpace(incident.VODURL)
+	if incident.VODURL != "" {
+		vodURL, err := url.Parse(incident.VODURL)
+		if err != nil || (vodURL.Scheme != "http" && vodURL.Scheme != "https") || vodURL.Host == "" {
+			return fmt.Errorf("invalid VOD link %s", incident.VODURL)
+		}
+	}
+
+	switch incident.Outcome {
+	case jsondb.PendingIncidentOutcome, jsondb.NoFurtherActionIncidentOutcome, jsondb.WarningIncidentOutcome:
+		incident.PenaltyReference = ""
+	case jsondb.PenaltyIncidentOutcome:
+		incident.PenaltyReference = strings.TrimSpace(incident.PenaltyReference)
+		if incident.PenaltyReference == "" {
+			return fmt.Errorf("penalty needs a reference")
+		}
+	default:
+		return fmt.Errorf("unknown incident outcome %d", incident.Outcome)
+	}
+
+	return nil
+}
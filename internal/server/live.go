@@ -0,0 +1,361 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type liveStartRequest struct {
+	TotalLaps uint64   `json:"total_laps"`
+	Order     []uint64 `json:"order"`
+}
+
+type liveUpdateRequest struct {
+	Lap        *uint64  `json:"lap"`
+	Order      []uint64 `json:"order"`
+	Retire     []uint64 `json:"retire"`
+	FastestLap *uint64  `json:"fastest_lap_driver_id"`
+}
+
+type liveEvent struct {
+	Event       *jsondb.RaceEvent
+	Assignments map[uint64]driverAssignment
+}
+
+// StartLiveSessionHandler opens the live session of an event and marks the event as in progress.
+// Without an order the session starts in the order of the starting grid or the event entrants.
+func StartLiveSessionHandler(repo jsondb.JsonDatabase, store *LiveSessionStore) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userInput := &liveStartRequest{}
+		if err := ctx.BindJSON(userInput); err != nil {
+			logrus.WithError(err).Warn("unable to get user input for live session")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		live, ok := loadLiveEvent(ctx, repo)
+		if !ok {
+			return
+		}
+
+		if live.Event.Status != jsondb.ScheduledEventStatus && live.Event.Status != jsondb.InProgressEventStatus {
+			ctx.AbortWithStatus(http.StatusConflict)
+			return
+		}
+
+		order := userInput.Order
+		if len(order) <= 0 {
+			order = make([]uint64, 0, len(live.Event.StartingGrid))
+			for _, grid := range live.Event.StartingGrid {
+				order = append(order, grid.DriverID)
+			}
+		}
+		if len(order) <= 0 {
+			teams, err := repo.ListTeams()
+			if err != nil {
+				logrus.WithError(err).Warn("unable to read teams")
+				ctx.AbortWithStatus(http.StatusInternalServerError)
+				return
+			}
+			season, err := eventSeason(repo, live.Event)
+			if err != nil {
+				logrus.WithError(err).Warn("unable to read season of event")
+				ctx.AbortWithStatus(http.StatusInternalServerError)
+				return
+			}
+			order = eventEntrants(*live.Event, teams, season)
+		}
+
+		if err := validateLiveOrder(order, nil, live.Assignments); err != nil {
+			logrus.WithError(err).Warn("invalid live order")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		now := time.Now().Unix()
+		session := liveSession{
+			EventID:   live.Event.ID,
+			TotalLaps: userInput.TotalLaps,
+			Order:     order,
+			StartedAt: now,
+			UpdatedAt: now,
+		}
+		if err := store.start(session); err != nil {
+			ctx.AbortWithStatus(http.StatusConflict)
+			return
+		}
+
+		live.Event.Status = jsondb.InProgressEventStatus
+		if err := repo.UpdateEvent(live.Event); err != nil {
+			logrus.WithError(err).Warn("unable to update event status")
+			store.remove(session.EventID)
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		respondLiveSession(ctx, repo, live, session)
+	}
+}
+
+// UpdateLiveSessionHandler changes the current lap, running order, retirements or fastest lap
+// of a live session. Omitted fields keep their value.
+func UpdateLiveSessionHandler(repo jsondb.JsonDatabase, store *LiveSessionStore) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userInput := &liveUpdateRequest{}
+		if err := ctx.BindJSON(userInput); err != nil {
+			logrus.WithError(err).Warn("unable to get user input for live session update")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		live, ok := loadLiveEvent(ctx, repo)
+		if !ok {
+			return
+		}
+
+		if _, ok := store.get(live.Event.ID); !ok {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		session, err := store.update(live.Event.ID, func(session *liveSession) error {
+			return applyLiveUpdate(session, userInput, live.Assignments)
+		})
+		if err != nil {
+			logrus.WithError(err).Warn("invalid live session update")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		respondLiveSession(ctx, repo, live, session)
+	}
+}
+
+func GetLiveSessionHandler(repo jsondb.JsonDatabase, store *LiveSessionStore) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		live, ok := loadLiveEvent(ctx, repo)
+		if !ok {
+			return
+		}
+
+		session, ok := store.get(live.Event.ID)
+		if !ok {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		respondLiveSession(ctx, repo, live, session)
+	}
+}
+
+// FinalizeLiveSessionHandler stores the live order as the results of the event and completes it.
+// Retired drivers are classified as DNF behind all running drivers.
+func FinalizeLiveSessionHandler(repo jsondb.JsonDatabase, store *LiveSessionStore) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		live, ok := loadLiveEvent(ctx, repo)
+		if !ok {
+			return
+		}
+
+		session, ok := store.take(live.Event.ID)
+		if !ok {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		live.Event.Results = buildResults(session.classification(), live.Event.Type, live.Assignments)
+		applyResultFlags(live.Event.Results, session.FastestLap, session.Retired, nil)
+		live.Event.Status = jsondb.CompletedEventStatus
+
+		if err := repo.UpdateEvent(live.Event); err != nil {
+			logrus.WithError(err).Warn("unable to store results of live session")
+			// the session keeps running so finalizing can be tried again
+			if err := store.start(session); err != nil {
+				logrus.WithError(err).Warn("unable to restore live session")
+			}
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		teamNameMap, driverNameMap, err := buildNameMaps(repo)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to generate name maps")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		branding, err := buildBrandingMaps(repo)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to generate branding maps")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, convertEventToResponse(*live.Event, teamNameMap, driverNameMap, branding))
+	}
+}
+
+// AbortLiveSessionHandler drops the live session without results and schedules the event again.
+func AbortLiveSessionHandler(repo jsondb.JsonDatabase, store *LiveSessionStore) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		live, ok := loadLiveEvent(ctx, repo)
+		if !ok {
+			return
+		}
+
+		if _, ok := store.take(live.Event.ID); !ok {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		if live.Event.Status == jsondb.InProgressEventStatus {
+			live.Event.Status = jsondb.ScheduledEventStatus
+			if err := repo.UpdateEvent(live.Event); err != nil {
+				logrus.WithError(err).Warn("unable to update event status")
+				ctx.AbortWithStatus(http.StatusInternalServerError)
+				return
+			}
+		}
+
+		ctx.Status(http.StatusOK)
+	}
+}
+
+// loadLiveEvent reads the event of the race_id parameter with the team and class assignments
+// of its drivers. The request is aborted if ok is false.
+func loadLiveEvent(ctx *gin.Context, repo jsondb.JsonDatabase) (live liveEvent, ok bool) {
+	raceID, err := strconv.Atoi(ctx.Param("race_id"))
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	event, err := repo.GetEvent(uint64(raceID))
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	teams, err := repo.ListTeams()
+	if err != nil {
+		logrus.WithError(err).Warn("unable to read teams")
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	season, err := eventSeason(repo, event)
+	if err != nil {
+		logrus.WithError(err).Warn("unable to read season of event")
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	assignments, err := buildDriverAssignments(teams, event.Lineups)
+	if err == nil {
+		err = assignClasses(assignments, season, event.ClassEntries)
+	}
+	if err != nil {
+		logrus.WithError(err).Warn("unable to assign drivers of event")
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	live = liveEvent{Event: event, Assignments: assignments}
+	ok = true
+	return
+}
+
+func eventSeason(repo jsondb.JsonDatabase, event *jsondb.RaceEvent) (*jsondb.Season, error) {
+	if event.SeasonID == 0 {
+		return nil, nil
+	}
+
+	return repo.GetSeason(event.SeasonID)
+}
+
+func applyLiveUpdate(session *liveSession, input *liveUpdateRequest, assignments map[uint64]driverAssignment) error {
+	for _, driverID := range input.Retire {
+		if !IDisInList(session.Order, driverID) {
+			return fmt.Errorf("driver %d is not running", driverID)
+		}
+
+		running := make([]uint64, 0, len(session.Order))
+		for _, id := range session.Order {
+			if id != driverID {
+				running = append(running, id)
+			}
+		}
+		session.Order = running
+		session.Retired = append(session.Retired, driverID)
+	}
+
+	if input.Order != nil {
+		if err := validateLiveOrder(input.Order, session.Retired, assignments); err != nil {
+			return err
+		}
+		// drivers can be added to the order but running drivers can not silently disappear
+		for _, driverID := range session.Order {
+			if !IDisInList(input.Order, driverID) {
+				return fmt.Errorf("running driver %d is missing from the order", driverID)
+			}
+		}
+		session.Order = input.Order
+	}
+
+	if input.Lap != nil {
+		if session.TotalLaps > 0 && *input.Lap > session.TotalLaps {
+			return fmt.Errorf("lap %d is after the last lap", *input.Lap)
+		}
+		session.Lap = *input.Lap
+	}
+
+	if input.FastestLap != nil {
+		if _, ok := assignments[*input.FastestLap]; !ok {
+			return fmt.Errorf("unknown driver %d", *input.FastestLap)
+		}
+		session.FastestLap = input.FastestLap
+	}
+
+	session.UpdatedAt = time.Now().Unix()
+	return nil
+}
+
+func validateLiveOrder(order []uint64, retired []uint64, assignments map[uint64]driverAssignment) error {
+	for index, driverID := range order {
+		if _, ok := assignments[driverID]; !ok {
+			return fmt.Errorf("unknown driver %d", driverID)
+		}
+		if IDisInList(order[:index], driverID) {
+			return fmt.Errorf("driver %d is listed multiple times", driverID)
+		}
+		if IDisInList(retired, driverID) {
+			return fmt.Errorf("driver %d already retired", driverID)
+		}
+	}
+
+	return nil
+}
+
+func respondLiveSession(ctx *gin.Context, repo jsondb.JsonDatabase, live liveEvent, session liveSession) {
+	teamNameMap, driverNameMap, err := buildNameMaps(repo)
+	if err != nil {
+		logrus.WithError(err).Warn("unable to generate name maps")
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	branding, err := buildBrandingMaps(repo)
+	if err != nil {
+		logrus.WithError(err).Warn("unable to generate branding maps")
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convertLiveSessionToResponse(session, live, teamNameMap, driverNameMap, branding))
+}
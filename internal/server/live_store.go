@@ -0,0 +1,113 @@
+package server
+
+import (
+	"fmt"
+	"sync"
+)
+
+// liveSession is the running order of an event during the race. Order holds the drivers
+// still running and Retired the drivers that dropped out in the order they retired.
+type liveSession struct {
+	EventID    uint64
+	Lap        uint64
+	TotalLaps  uint64
+	Order      []uint64
+	Retired    []uint64
+	FastestLap *uint64
+	StartedAt  int64
+	UpdatedAt  int64
+}
+
+// LiveSessionStore holds the live sessions of all running events in memory. Sessions do not
+// survive a restart of the server, only finalized results are stored.
+type LiveSessionStore struct {
+	lock     sync.RWMutex
+	sessions map[uint64]liveSession
+}
+
+func NewLiveSessionStore() *LiveSessionStore {
+	return &LiveSessionStore{sessions: make(map[uint64]liveSession)}
+}
+
+func (s *LiveSessionStore) get(eventID uint64) (liveSession, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	session, ok := s.sessions[eventID]
+	return session.copy(), ok
+}
+
+func (s *LiveSessionStore) start(session liveSession) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, ok := s.sessions[session.EventID]; ok {
+		return fmt.Errorf("event %d already has a live session", session.EventID)
+	}
+
+	s.sessions[session.EventID] = session.copy()
+	return nil
+}
+
+// update applies the change to the current session while holding the lock so concurrent
+// updates by multiple editors do not overwrite each other.
+func (s *LiveSessionStore) update(eventID uint64, change func(session *liveSession) error) (liveSession, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	session, ok := s.sessions[eventID]
+	if !ok {
+		return liveSession{}, fmt.Errorf("missing live session for event %d", eventID)
+	}
+
+	session = session.copy()
+	if err := change(&session); err != nil {
+		return liveSession{}, err
+	}
+
+	s.sessions[eventID] = session
+	return session.copy(), nil
+}
+
+// take removes the session and returns it. Only one of several concurrent callers gets the
+// session and no update can get lost in between.
+func (s *LiveSessionStore) take(eventID uint64) (liveSession, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	session, ok := s.sessions[eventID]
+	if ok {
+		delete(s.sessions, eventID)
+	}
+	return session, ok
+}
+
+func (s *LiveSessionStore) remove(eventID uint64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.sessions, eventID)
+}
+
+func (session liveSession) copy() liveSession {
+	session.Order = append([]uint64(nil), session.Order...)
+	session.Retired = append([]uint64(nil), session.Retired...)
+	if session.FastestLap != nil {
+		fastestLap := *session.FastestLap
+		session.FastestLap = &fastestLap
+	}
+
+	return session
+}
+
+// classification is the final order of the session with retired drivers behind all running
+// drivers and the last driver to retire classified first.
+func (session liveSession) classification() []uint64 {
+	order := make([]uint64, 0, len(session.Order)+len(session.Retired))
+	order = append(order, session.Order...)
+	for index := len(session.Retired) - 1; index >= 0; index-- {
+		order = append(order, session.Retired[index])
+	}
+
+	return order
+}
@@ -6,6 +6,9 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// editorContextKey holds the username of the authenticated editor in the gin context
+const editorContextKey = "editor"
+
 type EditorLogin struct {
 	Username string
 	Password string
@@ -22,6 +25,7 @@ func GetEditorMiddleware(editors []*EditorLogin) gin.HandlerFunc {
 
 		for _, editor := range editors {
 			if editor.Username == username && editor.Password == password {
+				ctx.Set(editorContextKey, editor.Username)
 				return
 			}
 		}
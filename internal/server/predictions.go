@@ -0,0 +1,278 @@
+package server
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	predictionPodiumSize = 3
+	maxViewerNameLength  = 64
+)
+
+type predictionRequest struct {
+	Viewer             string   `json:"viewer"`
+	Token              string   `json:"token"`
+	Podium             []uint64 `json:"podium"`
+	PoleDriverID       *uint64  `json:"pole_driver_id"`
+	FastestLapDriverID *uint64  `json:"fastest_lap_driver_id"`
+}
+
+// SubmitPredictionHandler creates or replaces the prediction of a viewer for an event. A
+// prediction can only be changed with the token it was created with, or without a token if
+// it was created without one.
+func SubmitPredictionHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userInput := &predictionRequest{}
+		if err := ctx.BindJSON(userInput); err != nil {
+			logrus.WithError(err).Warn("unable to get user input for prediction")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		raceID, err := strconv.Atoi(ctx.Param("race_id"))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		event, err := repo.GetEvent(uint64(raceID))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		if isPredictionLocked(*event, time.Now()) {
+			ctx.AbortWithStatus(http.StatusConflict)
+			return
+		}
+
+		teams, err := repo.ListTeams()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read teams")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		if err := validatePrediction(userInput, teams); err != nil {
+			logrus.WithError(err).Warn("invalid prediction")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		predictions, err := repo.ListPredictions()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read predictions")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		prediction := &jsondb.Prediction{
+			EventID:            event.ID,
+			Viewer:             normalizeViewer(userInput.Viewer),
+			Podium:             userInput.Podium,
+			PoleDriverID:       userInput.PoleDriverID,
+			FastestLapDriverID: userInput.FastestLapDriverID,
+			SubmittedAt:        time.Now().Unix(),
+		}
+		if userInput.Token != "" {
+			prediction.TokenHash = hashPredictionToken(userInput.Token)
+		}
+
+		existing := findPrediction(predictions, event.ID, prediction.Viewer)
+		if existing == nil {
+			if err := repo.AddPrediction(prediction); err != nil {
+				logrus.WithError(err).Warn("unable to add prediction")
+				ctx.AbortWithStatus(http.StatusInternalServerError)
+				return
+			}
+
+			ctx.JSON(http.StatusCreated, convertPredictionToResponse(*prediction, *event, nil))
+			return
+		}
+
+		// the token of a prediction can not be added or changed later, otherwise anyone could
+		// take over a prediction without token by using the same viewer name
+		if subtle.ConstantTimeCompare([]byte(existing.TokenHash), []byte(prediction.TokenHash)) != 1 {
+			ctx.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		prediction.ID = existing.ID
+		if err := repo.UpdatePrediction(prediction); err != nil {
+			logrus.WithError(err).Warn("unable to update prediction")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, convertPredictionToResponse(*prediction, *event, nil))
+	}
+}
+
+// GetEventPredictionsHandler lists the predictions of an event once predictions are locked.
+// Before that the list is empty so nobody can copy the predictions of others.
+func GetEventPredictionsHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		raceID, err := strconv.Atoi(ctx.Param("race_id"))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		event, err := repo.GetEvent(uint64(raceID))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		predictions, err := repo.ListPredictions()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read predictions")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		settings, err := repo.GetSettings()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read settings")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		resp := make([]predictionResponse, 0)
+		if !isPredictionLocked(*event, time.Now()) {
+			ctx.JSON(http.StatusOK, resp)
+			return
+		}
+
+		for _, p := range predictions {
+			if p.EventID == event.ID {
+				resp = append(resp, convertPredictionToResponse(p, *event, &settings.PredictionScoring))
+			}
+		}
+
+		ctx.JSON(http.StatusOK, resp)
+	}
+}
+
+// DeletePredictionHandler allows editors to remove abusive predictions.
+func DeletePredictionHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		predictionID, err := strconv.Atoi(ctx.Param("prediction_id"))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		if err := repo.DeletePrediction(uint64(predictionID)); err != nil {
+			logrus.WithError(err).Warn("unable to delete prediction")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		ctx.Status(http.StatusOK)
+	}
+}
+
+// GetPredictionLeaderboardHandler sums the prediction points of all viewers over the
+// completed events of the season given by the season_id query parameter.
+func GetPredictionLeaderboardHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		events, err := repo.ListEvents()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read events")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		season, ok := querySeason(ctx, repo)
+		if !ok {
+			return
+		}
+
+		predictions, err := repo.ListPredictions()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read predictions")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		settings, err := repo.GetSettings()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read settings")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		events = filterEvents(events, isInSeason(season))
+		ctx.JSON(http.StatusOK, convertPredictionLeaderboardToResponse(predictions, events, settings.PredictionScoring))
+	}
+}
+
+// isPredictionLocked is true once the event started or is no longer scheduled.
+func isPredictionLocked(event jsondb.RaceEvent, now time.Time) bool {
+	return event.Status != jsondb.ScheduledEventStatus || now.Unix() >= event.Date
+}
+
+func validatePrediction(input *predictionRequest, teams []jsondb.Team) error {
+	viewer := normalizeViewer(input.Viewer)
+	if viewer == "" || len(viewer) > maxViewerNameLength {
+		return fmt.Errorf("invalid viewer name")
+	}
+
+	drivers := make(map[uint64]bool)
+	for _, t := range teams {
+		for _, d := range t.Drivers {
+			drivers[d.ID] = true
+		}
+	}
+
+	if len(input.Podium) != predictionPodiumSize {
+		return fmt.Errorf("podium needs %d drivers", predictionPodiumSize)
+	}
+	for index, driverID := range input.Podium {
+		if !drivers[driverID] {
+			return fmt.Errorf("unknown driver %d on podium", driverID)
+		}
+		if IDisInList(input.Podium[:index], driverID) {
+			return fmt.Errorf("driver %d is on the podium multiple times", driverID)
+		}
+	}
+
+	for _, driverID := range []*uint64{input.PoleDriverID, input.FastestLapDriverID} {
+		if driverID != nil && !drivers[*driverID] {
+			return fmt.Errorf("unknown driver %d", *driverID)
+		}
+	}
+
+	return nil
+}
+
+func normalizeViewer(viewer string) string {
+	return strings.ToLower(strings.TrimSpace(viewer))
+}
+
+func hashPredictionToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func findPrediction(predictions []jsondb.Prediction, eventID uint64, viewer string) *jsondb.Prediction {
+	for index := range predictions {
+		if predictions[index].EventID == eventID && predictions[index].Viewer == viewer {
+			return &predictions[index]
+		}
+	}
+
+	return nil
+}
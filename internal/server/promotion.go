@@ -0,0 +1,198 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type seasonEndRequest struct {
+	Promote        uint64 `json:"promote"`
+	Relegate       uint64 `json:"relegate"`
+	NextSeasonName string `json:"next_season_name"`
+}
+
+// EndSeasonHandler promotes and relegates drivers between adjacent divisions based on the
+// final division standings and creates the next season with the resulting rosters. Every
+// season can only be ended once.
+func EndSeasonHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userInput := &seasonEndRequest{}
+		if err := ctx.BindJSON(userInput); err != nil {
+			logrus.WithError(err).Warn("unable to get user input for season end")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		seasonID, err := strconv.Atoi(ctx.Param("season_id"))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		season, err := repo.GetSeason(uint64(seasonID))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		if len(season.Divisions) <= 0 || userInput.NextSeasonName == "" {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		teams, err := repo.ListTeams()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read teams")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		events, err := repo.ListEvents()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read events")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		settings, err := repo.GetSettings()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read settings")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		seasonEvents := filterEvents(filterEvents(filterEvents(events, isInSeason(season)), isChampionshipEvent), isCompletedEvent)
+		finalOrders := make(map[uint64][]*standingsRow)
+		for _, division := range season.Divisions {
+			table := buildStandings(teams, filterEvents(seasonEvents, isInDivision(division.ID)), standingsConfig{
+				Settings: *settings,
+				DropRule: season.DropRule,
+				Roster:   division.DriverIDs,
+			})
+			finalOrders[division.ID] = filterRowsByIDs(table.Drivers, division.DriverIDs)
+		}
+
+		nextDivisions, moves := planDivisionMoves(season.Divisions, finalOrders, userInput.Promote, userInput.Relegate)
+
+		now := time.Now().Unix()
+		for index := range moves {
+			moves[index].Date = now
+		}
+
+		nextSeason := &jsondb.Season{
+			Name:          userInput.NextSeasonName,
+			DropRule:      season.DropRule,
+			Classes:       season.Classes,
+			ClassEntries:  season.ClassEntries,
+			Divisions:     nextDivisions,
+			DivisionMoves: moves,
+		}
+		if err := repo.AddFollowUpSeason(nextSeason, season.ID); err != nil {
+			if errors.Is(err, jsondb.ErrSeasonAlreadyEnded) {
+				ctx.AbortWithStatus(http.StatusConflict)
+				return
+			}
+			logrus.WithError(err).Warn("unable to add next season")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, nextSeason)
+	}
+}
+
+func filterRowsByIDs(rows []*standingsRow, ids []uint64) []*standingsRow {
+	filtered := make([]*standingsRow, 0, len(ids))
+	for _, row := range rows {
+		if IDisInList(ids, row.ID) {
+			filtered = append(filtered, row)
+		}
+	}
+
+	return filtered
+}
+
+// planDivisionMoves swaps the top drivers of every division with the bottom drivers of the
+// division one tier above. It returns the rosters for the next season and all moves.
+func planDivisionMoves(
+	divisions []jsondb.Division,
+	finalOrders map[uint64][]*standingsRow,
+	promote uint64,
+	relegate uint64,
+) ([]jsondb.Division, []jsondb.DivisionMove) {
+	sorted := make([]jsondb.Division, len(divisions))
+	copy(sorted, divisions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Tier < sorted[j].Tier
+	})
+
+	moves := make([]jsondb.DivisionMove, 0)
+	moved := make(map[uint64]bool)
+	for index := 1; index < len(sorted); index++ {
+		upper, lower := sorted[index-1], sorted[index]
+
+		lowerOrder := finalOrders[lower.ID]
+		for i := 0; i < int(promote) && i < len(lowerOrder); i++ {
+			row := lowerOrder[i]
+			moved[row.ID] = true
+			moves = append(moves, jsondb.DivisionMove{
+				DriverID:       row.ID,
+				Type:           jsondb.PromotedMoveType,
+				FinalPosition:  uint64(i + 1),
+				FromDivisionID: lower.ID,
+				ToDivisionID:   upper.ID,
+			})
+		}
+
+		upperOrder := finalOrders[upper.ID]
+		relegated := uint64(0)
+		for i := len(upperOrder) - 1; i >= 0 && relegated < relegate; i-- {
+			row := upperOrder[i]
+			// drivers that were just promoted into the upper division stay there
+			if moved[row.ID] {
+				continue
+			}
+
+			moved[row.ID] = true
+			relegated++
+			moves = append(moves, jsondb.DivisionMove{
+				DriverID:       row.ID,
+				Type:           jsondb.RelegatedMoveType,
+				FinalPosition:  uint64(i + 1),
+				FromDivisionID: upper.ID,
+				ToDivisionID:   lower.ID,
+			})
+		}
+	}
+
+	nextDivisions := make([]jsondb.Division, 0, len(sorted))
+	for _, division := range sorted {
+		roster := make([]uint64, 0, len(division.DriverIDs))
+		for _, driverID := range division.DriverIDs {
+			if !moved[driverID] {
+				roster = append(roster, driverID)
+			}
+		}
+		for _, move := range moves {
+			if move.ToDivisionID == division.ID {
+				roster = append(roster, move.DriverID)
+			}
+		}
+
+		nextDivisions = append(nextDivisions, jsondb.Division{
+			ID:        division.ID,
+			Name:      division.Name,
+			Tier:      division.Tier,
+			DriverIDs: roster,
+		})
+	}
+
+	return nextDivisions, moves
+}
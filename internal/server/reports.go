@@ -0,0 +1,265 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+	"github.com/gin-gonic/gin"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/sirupsen/logrus"
+	"github.com/yuin/goldmark"
+)
+
+const maxReportTitleLength = 200
+
+// reportPolicy strips everything from rendered reports that is not safe to embed into the website
+var reportPolicy = bluemonday.UGCPolicy()
+
+type reportRequest struct {
+	Title string             `json:"title"`
+	Body  string             `json:"body"`
+	State jsondb.ReportState `json:"state"`
+}
+
+// GetReportHandler serves the published report of an event as JSON. The format query parameter
+// set to markdown or html returns only the raw markdown or the sanitized HTML.
+func GetReportHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		serveReport(ctx, repo, false)
+	}
+}
+
+// GetDraftReportHandler serves the report of an event to editors regardless of its state.
+func GetDraftReportHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		serveReport(ctx, repo, true)
+	}
+}
+
+func serveReport(ctx *gin.Context, repo jsondb.JsonDatabase, includeDrafts bool) {
+	raceID, err := strconv.Atoi(ctx.Param("race_id"))
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	event, err := repo.GetEvent(uint64(raceID))
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	report, err := repo.GetReport(event.ID)
+	if err != nil || (!includeDrafts && report.State != jsondb.PublishedReportState) {
+		ctx.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if ctx.Query("format") == "markdown" {
+		// the markdown is unsanitized and must never be interpreted as HTML by browsers
+		ctx.Header("X-Content-Type-Options", "nosniff")
+		ctx.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(report.Body))
+		return
+	}
+
+	resp, err := convertReportToResponse(*report, *event)
+	if err != nil {
+		logrus.WithError(err).Warn("unable to render report")
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	if ctx.Query("format") == "html" {
+		ctx.Data(http.StatusOK, "text/html; charset=utf-8", []byte(resp.HTML))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// SaveReportHandler creates or updates the report of an event. The author is the editor
+// that created the report.
+func SaveReportHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userInput := &reportRequest{}
+		if err := ctx.BindJSON(userInput); err != nil {
+			logrus.WithError(err).Warn("unable to get user input for report")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		raceID, err := strconv.Atoi(ctx.Param("race_id"))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		event, err := repo.GetEvent(uint64(raceID))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		if err := validateReport(userInput); err != nil {
+			logrus.WithError(err).Warn("invalid report")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		now := time.Now().Unix()
+		report, err := repo.SaveReport(event.ID, func(report *jsondb.Report) {
+			if report.CreatedAt == 0 {
+				report.Author = ctx.GetString(editorContextKey)
+				report.CreatedAt = now
+			}
+
+			report.Title = userInput.Title
+			report.Body = userInput.Body
+			report.UpdatedAt = now
+			if userInput.State == jsondb.PublishedReportState && report.State != jsondb.PublishedReportState {
+				report.PublishedAt = now
+			}
+			if userInput.State == jsondb.DraftReportState {
+				report.PublishedAt = 0
+			}
+			report.State = userInput.State
+		})
+		if err != nil {
+			logrus.WithError(err).Warn("unable to save report")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		resp, err := convertReportToResponse(*report, *event)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to render report")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, resp)
+	}
+}
+
+func DeleteReportHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		raceID, err := strconv.Atoi(ctx.Param("race_id"))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		if err := repo.DeleteReport(uint64(raceID)); err != nil {
+			logrus.WithError(err).Warn("unable to delete report")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		ctx.Status(http.StatusOK)
+	}
+}
+
+// GetReportFeedHandler lists the published reports of the season given by the season_id
+// query parameter, newest first.
+func GetReportFeedHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		events, err := repo.ListEvents()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read events")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		season, ok := querySeason(ctx, repo)
+		if !ok {
+			return
+		}
+
+		reports, err := repo.ListReports()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read reports")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		eventMap := make(map[uint64]jsondb.RaceEvent)
+		for _, e := range filterEvents(events, isInSeason(season)) {
+			eventMap[e.ID] = e
+		}
+
+		published := make([]jsondb.Report, 0, len(reports))
+		for _, r := range reports {
+			if _, ok := eventMap[r.EventID]; ok && r.State == jsondb.PublishedReportState {
+				published = append(published, r)
+			}
+		}
+		sort.SliceStable(published, func(i, j int) bool {
+			return published[i].PublishedAt > published[j].PublishedAt
+		})
+
+		resp := make([]reportResponse, 0, len(published))
+		for _, r := range published {
+			reportResp, err := convertReportToResponse(r, eventMap[r.EventID])
+			if err != nil {
+				logrus.WithError(err).Warn("unable to render report")
+				ctx.AbortWithStatus(http.StatusInternalServerError)
+				return
+			}
+			resp = append(resp, reportResp)
+		}
+
+		ctx.JSON(http.StatusOK, resp)
+	}
+}
+
+func validateReport(input *reportRequest) error {
+	input.Title = strings.TrimSpace(input.Title)
+	if input.Title == "" || len(input.Title) > maxReportTitleLength {
+		return fmt.Errorf("invalid report title")
+	}
+
+	if input.State > jsondb.PublishedReportState {
+		return fmt.Errorf("unknown report state %d", input.State)
+	}
+
+	return nil
+}
+
+// renderReportHTML converts the markdown to HTML. Raw HTML inside of the markdown is dropped
+// by goldmark already and the result is sanitized on top of that.
+func renderReportHTML(markdown string) (string, error) {
+	var buf bytes.Buffer
+	if err := goldmark.Convert([]byte(markdown), &buf); err != nil {
+		return "", err
+	}
+
+	return reportPolicy.Sanitize(buf.String()), nil
+}
+
+func convertReportToResponse(report jsondb.Report, event jsondb.RaceEvent) (reportResponse, error) {
+	html, err := renderReportHTML(report.Body)
+	if err != nil {
+		return reportResponse{}, err
+	}
+
+	return reportResponse{
+		EventID:     event.ID,
+		EventName:   event.Name,
+		UnixDate:    event.Date,
+		SeasonID:    event.SeasonID,
+		Title:       report.Title,
+		Author:      report.Author,
+		State:       report.State.Name(),
+		Markdown:    report.Body,
+		HTML:        html,
+		CreatedAt:   report.CreatedAt,
+		UpdatedAt:   report.UpdatedAt,
+		PublishedAt: report.PublishedAt,
+	}, nil
+}
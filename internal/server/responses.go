@@ -5,17 +5,34 @@ type teamResultResponse struct {
 	DriverName string `json:"driver_name"`
 	Points     uint64 `json:"points"`
 	Position   uint64 `json:"position"`
+	Substitute bool   `json:"substitute"`
 }
 
 type driverResultResponse struct {
-	EventName string `json:"event_name"`
-	Points    uint64 `json:"points"`
-	Position  uint64 `json:"position"`
+	EventName  string `json:"event_name"`
+	Points     uint64 `json:"points"`
+	Position   uint64 `json:"position"`
+	Substitute bool   `json:"substitute"`
+}
+
+type teamBrandingResponse struct {
+	ShortName string `json:"short_name"`
+	Color     string `json:"color"`
+	LogoURL   string `json:"logo_url"`
+}
+
+type driverBrandingResponse struct {
+	Abbreviation string `json:"abbreviation"`
+	Nationality  string `json:"nationality"`
+	CarNumber    uint64 `json:"car_number,omitempty"`
+	PortraitURL  string `json:"portrait_url"`
 }
 
 type driverResponse struct {
-	ID                  uint64                 `json:"id"`
-	Name                string                 `json:"name"`
+	ID   uint64 `json:"id"`
+	Name string `json:"name"`
+	Role string `json:"role"`
+	driverBrandingResponse
 	Points              uint64                 `json:"points"`
 	PreSeasonPoints     uint64                 `json:"pre_season_points"`
 	PrevPoints          uint64                 `json:"prev_points"`
@@ -24,8 +41,10 @@ type driverResponse struct {
 }
 
 type teamResponse struct {
-	ID                  uint64               `json:"id"`
-	Name                string               `json:"name"`
+	ID       uint64 `json:"id"`
+	Position uint64 `json:"position,omitempty"`
+	Name     string `json:"name"`
+	teamBrandingResponse
 	Points              uint64               `json:"points"`
 	PreSeasonPoints     uint64               `json:"pre_season_points"`
 	PrevPoints          uint64               `json:"prev_points"`
@@ -35,25 +54,472 @@ type teamResponse struct {
 }
 
 type eventGridResponse struct {
-	DriverID   uint64 `json:"driver_id"`
-	DriverName string `json:"driver_name"`
-	TeamName   string `json:"team_name"`
-	Position   uint64 `json:"position"`
+	DriverID       uint64                 `json:"driver_id"`
+	DriverName     string                 `json:"driver_name"`
+	DriverBranding driverBrandingResponse `json:"driver_branding"`
+	TeamName       string                 `json:"team_name"`
+	TeamBranding   teamBrandingResponse   `json:"team_branding"`
+	Position       uint64                 `json:"position"`
+	Substitute     bool                   `json:"substitute"`
+	ClassID        uint64                 `json:"class_id,omitempty"`
 }
 
 type eventResultResponse struct {
-	DriverName string `json:"driver_name"`
-	DriverID   uint64 `json:"driver_id"`
-	TeamName   string `json:"team_name"`
-	Position   uint64 `json:"position"`
-	Points     uint64 `json:"points"`
+	DriverName     string                 `json:"driver_name"`
+	DriverID       uint64                 `json:"driver_id"`
+	DriverBranding driverBrandingResponse `json:"driver_branding"`
+	TeamName       string                 `json:"team_name"`
+	TeamBranding   teamBrandingResponse   `json:"team_branding"`
+	Position       uint64                 `json:"position"`
+	Points         uint64                 `json:"points"`
+	Substitute     bool                   `json:"substitute"`
+	Status         string                 `json:"status"`
+	FastestLap     bool                   `json:"fastest_lap"`
+	BestLapMS      uint64                 `json:"best_lap_ms,omitempty"`
+	IncidentPoints uint64                 `json:"incident_points,omitempty"`
+
+	ClassID       uint64 `json:"class_id,omitempty"`
+	ClassPosition uint64 `json:"class_position,omitempty"`
+	ClassPoints   uint64 `json:"class_points,omitempty"`
+
+	Laps          uint64            `json:"laps,omitempty"`
+	StartCompound string            `json:"start_compound,omitempty"`
+	PitStops      []pitStopResponse `json:"pit_stops,omitempty"`
+}
+
+type eventLineupResponse struct {
+	TeamID       uint64                      `json:"team_id"`
+	TeamName     string                      `json:"team_name"`
+	TeamBranding teamBrandingResponse        `json:"team_branding"`
+	Drivers      []eventLineupDriverResponse `json:"drivers"`
+}
+
+type eventLineupDriverResponse struct {
+	DriverID       uint64                 `json:"driver_id"`
+	DriverName     string                 `json:"driver_name"`
+	DriverBranding driverBrandingResponse `json:"driver_branding"`
 }
 
 type eventResponse struct {
 	ID           uint64                `json:"id"`
 	Name         string                `json:"name"`
 	Type         string                `json:"type"`
+	Status       string                `json:"status"`
+	SeasonID     uint64                `json:"season_id"`
 	UnixDate     int64                 `json:"race_date_unix"`
 	StartingGrid []eventGridResponse   `json:"starting_grid"`
 	Results      []eventResultResponse `json:"results"`
+	Lineups      []eventLineupResponse `json:"lineups"`
+
+	GridGeneration *gridGenerationResponse    `json:"grid_generation,omitempty"`
+	Conditions     *sessionConditionsResponse `json:"conditions,omitempty"`
+	ExternalID     string                     `json:"external_id,omitempty"`
+}
+
+type weatherPhaseResponse struct {
+	FromLap uint64 `json:"from_lap"`
+	Weather string `json:"weather"`
+}
+
+type sessionConditionsResponse struct {
+	Weather          []weatherPhaseResponse `json:"weather"`
+	AirTemperature   *float64               `json:"air_temperature_c,omitempty"`
+	TrackTemperature *float64               `json:"track_temperature_c,omitempty"`
+	TrackState       string                 `json:"track_state"`
+	TimeOfDay        string                 `json:"time_of_day,omitempty"`
+}
+
+type gridGenerationResponse struct {
+	Rule              string   `json:"rule"`
+	ReverseTopN       uint64   `json:"reverse_top_n,omitempty"`
+	Seed              int64    `json:"seed"`
+	StandingsEventIDs []uint64 `json:"standings_event_ids,omitempty"`
+	GeneratedAt       int64    `json:"generated_at_unix"`
+}
+
+type standingsResultResponse struct {
+	EventID   uint64 `json:"event_id"`
+	EventName string `json:"event_name"`
+	Position  uint64 `json:"position"`
+	Points    uint64 `json:"points"`
+	Dropped   bool   `json:"dropped"`
+}
+
+type standingsRowResponse struct {
+	Position    uint64                    `json:"position"`
+	ID          uint64                    `json:"id"`
+	Name        string                    `json:"name"`
+	Points      uint64                    `json:"points"`
+	GrossPoints uint64                    `json:"gross_points"`
+	Wins        uint64                    `json:"wins"`
+	DecidedBy   string                    `json:"decided_by,omitempty"`
+	Results     []standingsResultResponse `json:"results"`
+}
+
+type driverStandingsRowResponse struct {
+	standingsRowResponse
+	TeamID   uint64 `json:"team_id"`
+	TeamName string `json:"team_name"`
+}
+
+type standingsResponse struct {
+	Drivers []driverStandingsRowResponse `json:"drivers"`
+	Teams   []standingsRowResponse       `json:"teams"`
+}
+
+type progressionRowResponse struct {
+	ID             uint64 `json:"id"`
+	Name           string `json:"name"`
+	Position       uint64 `json:"position"`
+	Points         uint64 `json:"points"`
+	PositionChange int64  `json:"position_change"`
+}
+
+type progressionEventResponse struct {
+	ID   uint64 `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type progressionRoundResponse struct {
+	Round    uint64                     `json:"round"`
+	UnixDate int64                      `json:"race_date_unix"`
+	Events   []progressionEventResponse `json:"events"`
+	Drivers  []progressionRowResponse   `json:"drivers"`
+	Teams    []progressionRowResponse   `json:"teams"`
+}
+
+type teammateComparisonResponse struct {
+	DriverID    uint64 `json:"driver_id"`
+	DriverName  string `json:"driver_name"`
+	Events      uint64 `json:"events"`
+	RaceAhead   uint64 `json:"race_ahead"`
+	RaceBehind  uint64 `json:"race_behind"`
+	QualiAhead  uint64 `json:"quali_ahead"`
+	QualiBehind uint64 `json:"quali_behind"`
+}
+
+type driverStreaksResponse struct {
+	Wins     uint64 `json:"wins"`
+	Podiums  uint64 `json:"podiums"`
+	Points   uint64 `json:"points"`
+	Finishes uint64 `json:"finishes"`
+}
+
+type driverIncidentsResponse struct {
+	Total           uint64 `json:"total"`
+	NoFurtherAction uint64 `json:"no_further_action"`
+	Warnings        uint64 `json:"warnings"`
+	Penalties       uint64 `json:"penalties"`
+}
+
+type conditionStatsResponse struct {
+	Starts        uint64  `json:"starts"`
+	Wins          uint64  `json:"wins"`
+	Podiums       uint64  `json:"podiums"`
+	DNFs          uint64  `json:"dnfs"`
+	Points        uint64  `json:"points"`
+	AverageFinish float64 `json:"average_finish"`
+}
+
+type driverConditionsResponse struct {
+	Dry conditionStatsResponse `json:"dry"`
+	Wet conditionStatsResponse `json:"wet"`
+}
+
+type driverStatsResponse struct {
+	DriverID   uint64 `json:"driver_id"`
+	DriverName string `json:"driver_name"`
+	driverBrandingResponse
+	Starts          uint64                       `json:"starts"`
+	Wins            uint64                       `json:"wins"`
+	Podiums         uint64                       `json:"podiums"`
+	Poles           uint64                       `json:"poles"`
+	FastestLaps     uint64                       `json:"fastest_laps"`
+	DNFs            uint64                       `json:"dnfs"`
+	DSQs            uint64                       `json:"dsqs"`
+	Points          uint64                       `json:"points"`
+	AverageFinish   float64                      `json:"average_finish"`
+	AverageGrid     float64                      `json:"average_grid"`
+	PositionsGained int64                        `json:"positions_gained"`
+	CurrentStreaks  driverStreaksResponse        `json:"current_streaks"`
+	Incidents       driverIncidentsResponse      `json:"incidents"`
+	Conditions      driverConditionsResponse     `json:"conditions"`
+	Teammates       []teammateComparisonResponse `json:"teammates"`
+}
+
+type comparisonEntityResponse struct {
+	ID   uint64 `json:"id"`
+	Name string `json:"name"`
+}
+
+type comparisonEventResponse struct {
+	EventID          uint64    `json:"event_id"`
+	EventName        string    `json:"event_name"`
+	UnixDate         int64     `json:"race_date_unix"`
+	Positions        [2]uint64 `json:"positions"`
+	GridPositions    [2]uint64 `json:"grid_positions"`
+	Points           [2]uint64 `json:"points"`
+	FinishedAheadID  *uint64   `json:"finished_ahead_id"`
+	QualifiedAheadID *uint64   `json:"qualified_ahead_id"`
+	PointsDelta      int64     `json:"points_delta"`
+}
+
+type comparisonTallyResponse struct {
+	RaceWins  [2]uint64 `json:"race_wins"`
+	QualiWins [2]uint64 `json:"quali_wins"`
+	Points    [2]uint64 `json:"points"`
+}
+
+type comparisonResponse struct {
+	Entities [2]comparisonEntityResponse `json:"entities"`
+	Events   []comparisonEventResponse   `json:"events"`
+	Tally    comparisonTallyResponse     `json:"tally"`
+}
+
+type scenarioNextRoundResponse struct {
+	MaxPoints      uint64 `json:"max_points"`
+	CanClinch      bool   `json:"can_clinch"`
+	ClinchMargin   int64  `json:"clinch_margin"`
+	SurvivalMargin *int64 `json:"survival_margin,omitempty"`
+}
+
+type scenarioRowResponse struct {
+	Position   uint64                     `json:"position"`
+	ID         uint64                     `json:"id"`
+	Name       string                     `json:"name"`
+	Points     uint64                     `json:"points"`
+	MaxPoints  uint64                     `json:"max_points"`
+	Clinched   bool                       `json:"clinched"`
+	Eliminated bool                       `json:"eliminated"`
+	NextRound  *scenarioNextRoundResponse `json:"next_round,omitempty"`
+	Summary    string                     `json:"summary"`
+}
+
+type scenariosResponse struct {
+	RemainingEvents []progressionEventResponse `json:"remaining_events"`
+	Drivers         []scenarioRowResponse      `json:"drivers"`
+	Teams           []scenarioRowResponse      `json:"teams"`
+}
+
+type simulationRowResponse struct {
+	ID                    uint64    `json:"id"`
+	Name                  string    `json:"name"`
+	Position              uint64    `json:"position"`
+	Points                uint64    `json:"points"`
+	ExpectedPosition      float64   `json:"expected_position"`
+	PositionProbabilities []float64 `json:"position_probabilities"`
+}
+
+type simulationResponse struct {
+	Runs            int                        `json:"runs"`
+	Seed            int64                      `json:"seed"`
+	RemainingEvents []progressionEventResponse `json:"remaining_events"`
+	Drivers         []simulationRowResponse    `json:"drivers"`
+	Teams           []simulationRowResponse    `json:"teams"`
+}
+
+type predictionScoreResponse struct {
+	Podium     uint64 `json:"podium"`
+	Pole       uint64 `json:"pole"`
+	FastestLap uint64 `json:"fastest_lap"`
+	Total      uint64 `json:"total"`
+}
+
+type predictionResponse struct {
+	ID                 uint64                   `json:"id"`
+	EventID            uint64                   `json:"event_id"`
+	Viewer             string                   `json:"viewer"`
+	Podium             []uint64                 `json:"podium"`
+	PoleDriverID       *uint64                  `json:"pole_driver_id,omitempty"`
+	FastestLapDriverID *uint64                  `json:"fastest_lap_driver_id,omitempty"`
+	SubmittedAt        int64                    `json:"submitted_at"`
+	Score              *predictionScoreResponse `json:"score,omitempty"`
+}
+
+type predictionLeaderboardRowResponse struct {
+	Position    uint64 `json:"position"`
+	Viewer      string `json:"viewer"`
+	Points      uint64 `json:"points"`
+	Predictions uint64 `json:"predictions"`
+}
+
+type incidentDriverResponse struct {
+	DriverID   uint64 `json:"driver_id"`
+	DriverName string `json:"driver_name"`
+}
+
+type incidentResponse struct {
+	ID               uint64                   `json:"id"`
+	EventID          uint64                   `json:"event_id"`
+	EventName        string                   `json:"event_name"`
+	Lap              uint64                   `json:"lap,omitempty"`
+	Drivers          []incidentDriverResponse `json:"drivers"`
+	Description      string                   `json:"description"`
+	ReplayTimestamp  uint64                   `json:"replay_timestamp_seconds,omitempty"`
+	VODURL           string                   `json:"vod_url,omitempty"`
+	Outcome          string                   `json:"outcome"`
+	PenaltyReference string                   `json:"penalty_reference,omitempty"`
+}
+
+type pitStopResponse struct {
+	Lap              uint64 `json:"lap"`
+	StationaryTimeMS uint64 `json:"stationary_time_ms"`
+	ServiceTimeMS    uint64 `json:"service_time_ms"`
+	Compound         string `json:"compound"`
+	PenaltyServedMS  uint64 `json:"penalty_served_ms,omitempty"`
+}
+
+type stintResponse struct {
+	Stint    uint64 `json:"stint"`
+	Compound string `json:"compound"`
+	StartLap uint64 `json:"start_lap"`
+	EndLap   uint64 `json:"end_lap,omitempty"`
+	Laps     uint64 `json:"laps,omitempty"`
+}
+
+type driverStrategyResponse struct {
+	DriverID   uint64            `json:"driver_id"`
+	DriverName string            `json:"driver_name"`
+	TeamID     uint64            `json:"team_id"`
+	TeamName   string            `json:"team_name"`
+	Position   uint64            `json:"position"`
+	Status     string            `json:"status"`
+	Laps       uint64            `json:"laps,omitempty"`
+	Stops      uint64            `json:"stops"`
+	Stints     []stintResponse   `json:"stints"`
+	PitStops   []pitStopResponse `json:"pit_stops"`
+}
+
+type teamPitStopStatsResponse struct {
+	TeamID                uint64  `json:"team_id"`
+	TeamName              string  `json:"team_name"`
+	Stops                 uint64  `json:"stops"`
+	FastestStopMS         uint64  `json:"fastest_stop_ms,omitempty"`
+	FastestStopDriverID   uint64  `json:"fastest_stop_driver_id"`
+	FastestStopDriverName string  `json:"fastest_stop_driver_name"`
+	FastestStopEventID    uint64  `json:"fastest_stop_event_id"`
+	FastestStopEventName  string  `json:"fastest_stop_event_name"`
+	AverageStopMS         float64 `json:"average_stop_ms"`
+}
+
+type eventStrategyResponse struct {
+	EventID   uint64                     `json:"event_id"`
+	EventName string                     `json:"event_name"`
+	Drivers   []driverStrategyResponse   `json:"drivers"`
+	Teams     []teamPitStopStatsResponse `json:"teams"`
+}
+
+type reportResponse struct {
+	EventID     uint64 `json:"event_id"`
+	EventName   string `json:"event_name"`
+	UnixDate    int64  `json:"race_date_unix"`
+	SeasonID    uint64 `json:"season_id"`
+	Title       string `json:"title"`
+	Author      string `json:"author"`
+	State       string `json:"state"`
+	Markdown    string `json:"markdown"`
+	HTML        string `json:"html"`
+	CreatedAt   int64  `json:"created_at"`
+	UpdatedAt   int64  `json:"updated_at"`
+	PublishedAt int64  `json:"published_at,omitempty"`
+}
+
+type liveEntryResponse struct {
+	Position        uint64                 `json:"position"`
+	DriverID        uint64                 `json:"driver_id"`
+	DriverName      string                 `json:"driver_name"`
+	DriverBranding  driverBrandingResponse `json:"driver_branding"`
+	TeamID          uint64                 `json:"team_id"`
+	TeamName        string                 `json:"team_name"`
+	TeamBranding    teamBrandingResponse   `json:"team_branding"`
+	Retired         bool                   `json:"retired"`
+	FastestLap      bool                   `json:"fastest_lap"`
+	PositionsGained int64                  `json:"positions_gained"`
+}
+
+type liveSessionResponse struct {
+	EventID   uint64              `json:"event_id"`
+	EventName string              `json:"event_name"`
+	Lap       uint64              `json:"lap"`
+	TotalLaps uint64              `json:"total_laps,omitempty"`
+	StartedAt int64               `json:"started_at"`
+	UpdatedAt int64               `json:"updated_at"`
+	Entries   []liveEntryResponse `json:"entries"`
+}
+
+type aliasResponse struct {
+	ID         uint64 `json:"id"`
+	Type       string `json:"type"`
+	Value      string `json:"value"`
+	DriverID   uint64 `json:"driver_id"`
+	DriverName string `json:"driver_name"`
+}
+
+type unmatchedDriversResponse struct {
+	Unmatched []UnmatchedDriver `json:"unmatched"`
+}
+
+type csvImportErrorsResponse struct {
+	Errors []CSVLineError `json:"errors"`
+}
+
+type fieldChangeResponse struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+type positionSnapshotResponse struct {
+	Position      uint64 `json:"position"`
+	Points        uint64 `json:"points"`
+	TeamID        uint64 `json:"team_id"`
+	Status        string `json:"status"`
+	FastestLap    bool   `json:"fastest_lap"`
+	BestLapMS     uint64 `json:"best_lap_ms,omitempty"`
+	ClassID       uint64 `json:"class_id,omitempty"`
+	ClassPosition uint64 `json:"class_position,omitempty"`
+	Laps          uint64 `json:"laps,omitempty"`
+	PitStops      int    `json:"pit_stops"`
+}
+
+type positionChangeResponse struct {
+	DriverID   uint64                    `json:"driver_id"`
+	DriverName string                    `json:"driver_name"`
+	Change     string                    `json:"change"`
+	Old        *positionSnapshotResponse `json:"old,omitempty"`
+	New        *positionSnapshotResponse `json:"new,omitempty"`
+}
+
+type eventDiffResponse struct {
+	Fields       []fieldChangeResponse    `json:"fields"`
+	StartingGrid []positionChangeResponse `json:"starting_grid"`
+	Results      []positionChangeResponse `json:"results"`
+}
+
+type importPreviewSessionResponse struct {
+	Session  string             `json:"session"`
+	Action   string             `json:"action"`
+	Event    eventResponse      `json:"event"`
+	Changes  *eventDiffResponse `json:"changes,omitempty"`
+	Warnings []string           `json:"warnings"`
+}
+
+type importPreviewResponse struct {
+	Sessions  []importPreviewSessionResponse `json:"sessions"`
+	Unmatched []UnmatchedDriver              `json:"unmatched"`
+}
+
+type bundleImportResponse struct {
+	Mode      string   `json:"mode"`
+	SeasonID  uint64   `json:"season_id,omitempty"`
+	Teams     uint64   `json:"teams"`
+	Drivers   uint64   `json:"drivers"`
+	Seasons   uint64   `json:"seasons"`
+	Events    uint64   `json:"events"`
+	Incidents uint64   `json:"incidents"`
+	Reports   uint64   `json:"reports"`
+	Aliases   uint64   `json:"aliases"`
+	Assets    uint64   `json:"assets"`
+	Warnings  []string `json:"warnings"`
 }
@@ -0,0 +1,285 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func GetSeasonsHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		seasons, err := repo.ListSeasons()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read seasons")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		if seasons == nil {
+			seasons = make([]jsondb.Season, 0)
+		}
+
+		ctx.JSON(http.StatusOK, seasons)
+	}
+}
+
+func GetSeasonHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		seasonID, err := strconv.Atoi(ctx.Param("season_id"))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		season, err := repo.GetSeason(uint64(seasonID))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, season)
+	}
+}
+
+func AddSeasonHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		newSeason := &jsondb.Season{}
+
+		if err := ctx.BindJSON(newSeason); err != nil {
+			logrus.WithError(err).Warn("unable to get user input for new season")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		if err := validateSeason(newSeason); err != nil {
+			logrus.WithError(err).Warn("invalid season")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		// moves and the follow-up season are only recorded by the season end procedure
+		newSeason.DivisionMoves = nil
+		newSeason.NextSeasonID = 0
+		if err := repo.AddSeason(newSeason); err != nil {
+			logrus.WithError(err).Warn("unable to add season")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, newSeason)
+	}
+}
+
+func UpdateSeasonHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userInputSeason := &jsondb.Season{}
+
+		if err := ctx.BindJSON(userInputSeason); err != nil {
+			logrus.WithError(err).Warn("unable to get user input for season update")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		seasonID, err := strconv.Atoi(ctx.Param("season_id"))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		if err := validateSeason(userInputSeason); err != nil {
+			logrus.WithError(err).Warn("invalid season")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		existing, err := repo.GetSeason(uint64(seasonID))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		userInputSeason.ID = existing.ID
+		userInputSeason.DivisionMoves = existing.DivisionMoves
+		userInputSeason.NextSeasonID = existing.NextSeasonID
+		if err := repo.UpdateSeason(userInputSeason); err != nil {
+			logrus.WithError(err).Warn("unable to update season")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		ctx.Status(http.StatusOK)
+	}
+}
+
+// DeleteSeasonHandler only deletes seasons without events. Events have to be moved to
+// another season or deleted first.
+func DeleteSeasonHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		seasonID, err := strconv.Atoi(ctx.Param("season_id"))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		events, err := repo.ListEvents()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read events")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		for _, e := range events {
+			if e.SeasonID == uint64(seasonID) {
+				ctx.AbortWithStatus(http.StatusConflict)
+				return
+			}
+		}
+
+		if err := repo.DeleteSeason(uint64(seasonID)); err != nil {
+			logrus.WithError(err).Warn("unable to delete season")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		ctx.Status(http.StatusOK)
+	}
+}
+
+// querySeason reads the optional season_id query parameter. A nil season without
+// abort means no season was requested.
+func querySeason(ctx *gin.Context, repo jsondb.JsonDatabase) (*jsondb.Season, bool) {
+	seasonParam := ctx.Query("season_id")
+	if seasonParam == "" {
+		return nil, true
+	}
+
+	seasonID, err := strconv.Atoi(seasonParam)
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusBadRequest)
+		return nil, false
+	}
+
+	season, err := repo.GetSeason(uint64(seasonID))
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusNotFound)
+		return nil, false
+	}
+
+	return season, true
+}
+
+func isInSeason(season *jsondb.Season) func(e jsondb.RaceEvent) bool {
+	return func(e jsondb.RaceEvent) bool {
+		return season == nil || e.SeasonID == season.ID
+	}
+}
+
+func isInDivision(divisionID uint64) func(e jsondb.RaceEvent) bool {
+	return func(e jsondb.RaceEvent) bool {
+		return e.DivisionID == divisionID
+	}
+}
+
+func validateSeason(season *jsondb.Season) error {
+	if season.DropRule != nil {
+		if season.DropRule.Mode != jsondb.DropWorstMode && season.DropRule.Mode != jsondb.CountBestMode {
+			return fmt.Errorf("unknown drop rule mode %d", season.DropRule.Mode)
+		}
+		if season.DropRule.Count == 0 {
+			return fmt.Errorf("drop rule needs a count")
+		}
+	}
+
+	assignClassIDs(season)
+	classIDs := make(map[uint64]bool)
+	for _, class := range season.Classes {
+		if classIDs[class.ID] {
+			return fmt.Errorf("duplicate class ID %d", class.ID)
+		}
+		classIDs[class.ID] = true
+	}
+
+	if err := validateClassEntries(season, season.ClassEntries); err != nil {
+		return err
+	}
+
+	assignDivisionIDs(season)
+	divisionIDs := make(map[uint64]bool)
+	tiers := make(map[uint64]bool)
+	divisionDrivers := make(map[uint64]bool)
+	for _, division := range season.Divisions {
+		if divisionIDs[division.ID] {
+			return fmt.Errorf("duplicate division ID %d", division.ID)
+		}
+		divisionIDs[division.ID] = true
+
+		if division.Tier == 0 || tiers[division.Tier] {
+			return fmt.Errorf("division %d needs a unique tier starting at 1", division.ID)
+		}
+		tiers[division.Tier] = true
+
+		for _, driverID := range division.DriverIDs {
+			if divisionDrivers[driverID] {
+				return fmt.Errorf("driver %d is part of multiple divisions", driverID)
+			}
+			divisionDrivers[driverID] = true
+		}
+	}
+
+	return nil
+}
+
+// assignDivisionIDs gives new divisions without an ID the next free ID of the season.
+func assignDivisionIDs(season *jsondb.Season) {
+	var maxID uint64
+	for _, division := range season.Divisions {
+		if division.ID > maxID {
+			maxID = division.ID
+		}
+	}
+
+	for index := range season.Divisions {
+		if season.Divisions[index].ID == 0 {
+			maxID++
+			season.Divisions[index].ID = maxID
+		}
+		if season.Divisions[index].DriverIDs == nil {
+			season.Divisions[index].DriverIDs = make([]uint64, 0)
+		}
+	}
+}
+
+func validateClassEntries(season *jsondb.Season, entries []jsondb.ClassEntry) error {
+	assignedDrivers := make(map[uint64]bool)
+	for _, entry := range entries {
+		if !season.HasClass(entry.ClassID) {
+			return fmt.Errorf("unknown class %d for driver %d", entry.ClassID, entry.DriverID)
+		}
+		if assignedDrivers[entry.DriverID] {
+			return fmt.Errorf("driver %d is assigned to multiple classes", entry.DriverID)
+		}
+		assignedDrivers[entry.DriverID] = true
+	}
+
+	return nil
+}
+
+// assignClassIDs gives new classes without an ID the next free ID of the season.
+func assignClassIDs(season *jsondb.Season) {
+	var maxID uint64
+	for _, class := range season.Classes {
+		if class.ID > maxID {
+			maxID = class.ID
+		}
+	}
+
+	for index := range season.Classes {
+		if season.Classes[index].ID == 0 {
+			maxID++
+			season.Classes[index].ID = maxID
+		}
+	}
+}
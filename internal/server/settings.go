@@ -0,0 +1,77 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func GetSettingsHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		settings, err := repo.GetSettings()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read settings")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, settings)
+	}
+}
+
+func UpdateSettingsHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		settings, err := repo.GetSettings()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read settings before update")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		// bind onto the existing settings so omitted fields keep their value. lists are
+		// detached first as json would otherwise merge into the existing elements
+		existingTieBreakers := settings.TieBreakers
+		settings.TieBreakers = nil
+		if err := ctx.BindJSON(settings); err != nil {
+			logrus.WithError(err).Warn("unable to get user input for settings")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		if settings.TieBreakers == nil {
+			settings.TieBreakers = existingTieBreakers
+		}
+
+		if err := validateSettings(settings); err != nil {
+			logrus.WithError(err).Warn("invalid settings")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		if err := repo.UpdateSettings(settings); err != nil {
+			logrus.WithError(err).Warn("unable to update settings")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		ctx.Status(http.StatusOK)
+	}
+}
+
+func validateSettings(settings *jsondb.Settings) error {
+	for _, tieBreaker := range settings.TieBreakers {
+		switch tieBreaker.Rule {
+		case jsondb.MostFinishesTieBreakRule:
+			if tieBreaker.Position == 0 {
+				return fmt.Errorf("tie breaker %d needs a position", tieBreaker.Rule)
+			}
+		case jsondb.CountbackTieBreakRule, jsondb.BestResultTieBreakRule, jsondb.LastRaceTieBreakRule:
+		default:
+			return fmt.Errorf("unknown tie breaker rule %d", tieBreaker.Rule)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,208 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+func GetStandingsHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		input, ok := loadStandingsInput(ctx, repo)
+		if !ok {
+			return
+		}
+
+		teamNameMap, _, err := buildNameMaps(repo)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to generate name maps")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		table := buildStandings(input.Teams, input.Events, input.Config)
+		standingsResp := convertStandingsToResponse(table, input.Events, teamNameMap)
+		if wantsCSV(ctx) {
+			respondCSV(ctx, "standings.csv", convertStandingsToCSV(standingsResp))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, standingsResp)
+	}
+}
+
+func GetStandingsProgressionHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		input, ok := loadStandingsInput(ctx, repo)
+		if !ok {
+			return
+		}
+
+		rounds := groupEventsIntoRounds(input.Events)
+		ctx.JSON(http.StatusOK, convertProgressionToResponse(input.Teams, rounds, input.Config))
+	}
+}
+
+func GetStandingsScenariosHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		input, ok := loadStandingsInput(ctx, repo)
+		if !ok {
+			return
+		}
+
+		table := buildStandings(input.Teams, input.Events, input.Config)
+		ctx.JSON(http.StatusOK, convertScenariosToResponse(table, input.Teams, input.Remaining, input.Config))
+	}
+}
+
+const (
+	defaultSimulationRuns = 1000
+	maxSimulationRuns     = 10000
+)
+
+// GetStandingsSimulationHandler simulates the remaining events of the championship with the
+// runs and seed query parameters. The same seed always returns the same probabilities. Large
+// simulations are expensive, so the endpoint is only open to editors.
+func GetStandingsSimulationHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		input, ok := loadStandingsInput(ctx, repo)
+		if !ok {
+			return
+		}
+
+		runs := defaultSimulationRuns
+		if runsParam := ctx.Query("runs"); runsParam != "" {
+			var err error
+			runs, err = strconv.Atoi(runsParam)
+			if err != nil || runs < 1 || runs > maxSimulationRuns {
+				ctx.AbortWithStatus(http.StatusBadRequest)
+				return
+			}
+		}
+
+		seed := time.Now().UnixNano()
+		if seedParam := ctx.Query("seed"); seedParam != "" {
+			var err error
+			seed, err = strconv.ParseInt(seedParam, 10, 64)
+			if err != nil {
+				ctx.AbortWithStatus(http.StatusBadRequest)
+				return
+			}
+		}
+
+		// finishing distributions use every completed championship event, not only the filtered ones
+		history, err := repo.ListEvents()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read events")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		history = filterEvents(filterEvents(history, isCompletedEvent), isChampionshipEvent)
+
+		templates, err := buildSimulationTemplates(input.Remaining, input.Teams, input.Season)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to prepare remaining events for simulation")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		baseline := buildStandings(input.Teams, input.Events, input.Config)
+		tally := simulateChampionship(input, baseline, templates, buildFinishingDistributions(history), runs, seed)
+		ctx.JSON(http.StatusOK, convertSimulationToResponse(baseline, tally, templates, runs, seed))
+	}
+}
+
+type standingsInput struct {
+	Teams []jsondb.Team
+	// Events are the completed events counting for the standings
+	Events []jsondb.RaceEvent
+	// Remaining are the scheduled, running and postponed events of the same championship
+	Remaining []jsondb.RaceEvent
+	Config    standingsConfig
+	Season    *jsondb.Season
+}
+
+// loadStandingsInput reads everything needed to calculate standings and applies the
+// season_id, division_id, class_id and pre_season query parameters. The request is aborted if ok is false.
+func loadStandingsInput(ctx *gin.Context, repo jsondb.JsonDatabase) (input standingsInput, ok bool) {
+	teams, err := repo.ListTeams()
+	if err != nil {
+		logrus.WithError(err).Warn("unable to read teams")
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	events, err := repo.ListEvents()
+	if err != nil {
+		logrus.WithError(err).Warn("unable to read events")
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	settings, err := repo.GetSettings()
+	if err != nil {
+		logrus.WithError(err).Warn("unable to read settings")
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	season, seasonOk := querySeason(ctx, repo)
+	if !seasonOk {
+		return
+	}
+
+	eventFilter := isChampionshipEvent
+	if ctx.Query("pre_season") == "true" {
+		eventFilter = isPreSeasonEvent
+	}
+	events = filterEvents(filterEvents(events, isInSeason(season)), eventFilter)
+
+	config := standingsConfig{Settings: *settings}
+	if season != nil {
+		config.DropRule = season.DropRule
+	}
+
+	if divisionParam := ctx.Query("division_id"); divisionParam != "" {
+		divisionID, err := strconv.Atoi(divisionParam)
+		if err != nil || season == nil {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		division, divisionOk := season.GetDivision(uint64(divisionID))
+		if !divisionOk {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		events = filterEvents(events, isInDivision(division.ID))
+		config.Roster = division.DriverIDs
+	}
+
+	if classParam := ctx.Query("class_id"); classParam != "" {
+		classID, err := strconv.Atoi(classParam)
+		if err != nil || season == nil {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		if !season.HasClass(uint64(classID)) {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		config.ClassID = uint64(classID)
+	}
+
+	input = standingsInput{
+		Teams:  teams,
+		Events: filterEvents(events, isCompletedEvent),
+		Remaining: filterEvents(events, func(e jsondb.RaceEvent) bool {
+			return isUpcomingEvent(e) || e.Status == jsondb.PostponedEventStatus
+		}),
+		Config: config,
+		Season: season,
+	}
+	ok = true
+	return
+}
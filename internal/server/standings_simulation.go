@@ -0,0 +1,182 @@
+package server
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+)
+
+// simulationTemplate is a remaining event with its entrants and their team and class assignments.
+type simulationTemplate struct {
+	Event       jsondb.RaceEvent
+	Entrants    []uint64
+	Assignments map[uint64]driverAssignment
+}
+
+// simulationEntry counts how often a driver or team finished the championship in each position.
+// Positions[0] are the titles.
+type simulationEntry struct {
+	ID        uint64
+	Name      string
+	Positions []uint64
+}
+
+type simulationTally struct {
+	Drivers []*simulationEntry
+	Teams   []*simulationEntry
+}
+
+func buildSimulationTemplates(remaining []jsondb.RaceEvent, teams []jsondb.Team, season *jsondb.Season) ([]simulationTemplate, error) {
+	templates := make([]simulationTemplate, 0, len(remaining))
+	for _, e := range sortEventsByDate(remaining) {
+		assignments, err := buildDriverAssignments(teams, e.Lineups)
+		if err != nil {
+			return nil, err
+		}
+		if err := assignClasses(assignments, season, e.ClassEntries); err != nil {
+			return nil, err
+		}
+
+		templates = append(templates, simulationTemplate{
+			Event:       e,
+			Entrants:    eventEntrants(e, teams, season),
+			Assignments: assignments,
+		})
+	}
+
+	return templates, nil
+}
+
+// buildFinishingDistributions collects the relative finishing positions of every driver with 0
+// being a win and 1 the back of the field. Results without a classified finish count as 1.
+func buildFinishingDistributions(events []jsondb.RaceEvent) map[uint64][]float64 {
+	distributions := make(map[uint64][]float64)
+	for _, e := range events {
+		fieldSize := len(e.Results)
+		for _, result := range e.Results {
+			sample := 1.0
+			if result.Status == jsondb.FinishedResultStatus && fieldSize > 1 {
+				sample = float64(result.Position-1) / float64(fieldSize-1)
+			}
+			distributions[result.DriverID] = append(distributions[result.DriverID], sample)
+		}
+	}
+
+	return distributions
+}
+
+// simulateChampionship finishes the remaining events runs times with finishing orders sampled from
+// the distributions and counts the resulting championship positions.
+func simulateChampionship(
+	input standingsInput,
+	baseline standings,
+	templates []simulationTemplate,
+	distributions map[uint64][]float64,
+	runs int,
+	seed int64,
+) simulationTally {
+	rng := rand.New(rand.NewSource(seed))
+	drivers := newSimulationCounter(baseline.Drivers)
+	teams := newSimulationCounter(baseline.Teams)
+
+	for run := 0; run < runs; run++ {
+		events := make([]jsondb.RaceEvent, len(input.Events), len(input.Events)+len(templates))
+		copy(events, input.Events)
+
+		for _, template := range templates {
+			order := simulateFinishingOrder(template.Entrants, distributions, rng)
+
+			e := template.Event
+			e.Status = jsondb.CompletedEventStatus
+			e.Results = buildResults(order, e.Type, template.Assignments)
+			events = append(events, e)
+		}
+
+		table := buildStandings(input.Teams, events, input.Config)
+		drivers.count(table.Drivers)
+		teams.count(table.Teams)
+	}
+
+	return simulationTally{
+		Drivers: drivers.Entries,
+		Teams:   teams.Entries,
+	}
+}
+
+// simulateFinishingOrder draws one historical result for every entrant and sorts them by it.
+// Entrants without any history get a random result.
+func simulateFinishingOrder(entrants []uint64, distributions map[uint64][]float64, rng *rand.Rand) []uint64 {
+	type draw struct {
+		DriverID uint64
+		Score    float64
+		TieBreak float64
+	}
+
+	draws := make([]draw, 0, len(entrants))
+	for _, driverID := range entrants {
+		d := draw{DriverID: driverID}
+		if samples := distributions[driverID]; len(samples) > 0 {
+			d.Score = samples[rng.Intn(len(samples))]
+		} else {
+			d.Score = rng.Float64()
+		}
+		d.TieBreak = rng.Float64()
+		draws = append(draws, d)
+	}
+
+	sort.SliceStable(draws, func(i, j int) bool {
+		if draws[i].Score != draws[j].Score {
+			return draws[i].Score < draws[j].Score
+		}
+		return draws[i].TieBreak < draws[j].TieBreak
+	})
+
+	order := make([]uint64, 0, len(draws))
+	for _, d := range draws {
+		order = append(order, d.DriverID)
+	}
+
+	return order
+}
+
+type simulationCounter struct {
+	Entries []*simulationEntry
+	byID    map[uint64]*simulationEntry
+}
+
+// newSimulationCounter keeps the order of the current standings. Entries that only show up
+// during the simulation are added at the end.
+func newSimulationCounter(rows []*standingsRow) *simulationCounter {
+	counter := &simulationCounter{
+		Entries: make([]*simulationEntry, 0, len(rows)),
+		byID:    make(map[uint64]*simulationEntry),
+	}
+	for _, row := range rows {
+		counter.entry(row)
+	}
+
+	return counter
+}
+
+func (c *simulationCounter) entry(row *standingsRow) *simulationEntry {
+	if entry, ok := c.byID[row.ID]; ok {
+		return entry
+	}
+
+	entry := &simulationEntry{ID: row.ID, Name: row.Name, Positions: make([]uint64, 0)}
+	c.byID[row.ID] = entry
+	c.Entries = append(c.Entries, entry)
+
+	return entry
+}
+
+func (c *simulationCounter) count(rows []*standingsRow) {
+	for _, row := range rows {
+		entry := c.entry(row)
+		for uint64(len(entry.Positions)) < row.Position {
+			entry.Positions = append(entry.Positions, 0)
+		}
+		entry.Positions[row.Position-1]++
+	}
+}
@@ -0,0 +1,422 @@
+package server
+
+import (
+	"sort"
+	"time"
+
+	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+)
+
+type standingsResult struct {
+	EventID   uint64
+	EventType jsondb.EventType
+	Date      int64
+	Position  uint64
+	Points    uint64
+	Dropped   bool
+}
+
+type standingsRow struct {
+	ID          uint64
+	Name        string
+	TeamID      uint64
+	Points      uint64
+	GrossPoints uint64
+	Results     []standingsResult
+	Position    uint64
+	DecidedBy   string
+}
+
+// standingsConfig controls how standings are calculated. A ClassID other than 0 builds
+// the standings of a single car class from the class positions and class points.
+// A non nil Roster lists exactly those drivers plus everyone who scored.
+type standingsConfig struct {
+	Settings jsondb.Settings
+	DropRule *jsondb.DropRule
+	ClassID  uint64
+	Roster   []uint64
+}
+
+type standings struct {
+	Drivers []*standingsRow
+	Teams   []*standingsRow
+}
+
+func isChampionshipEvent(e jsondb.RaceEvent) bool {
+	return e.Type == jsondb.RaceEventType || e.Type == jsondb.SprintEventType
+}
+
+func isPreSeasonEvent(e jsondb.RaceEvent) bool {
+	return e.Type == jsondb.PreSeason || e.Type == jsondb.PreSeasonSprintType
+}
+
+func isQualifyingEvent(e jsondb.RaceEvent) bool {
+	return e.Type == jsondb.QualifyingEventType
+}
+
+func isCompletedEvent(e jsondb.RaceEvent) bool {
+	return e.Status == jsondb.CompletedEventStatus
+}
+
+// isUpcomingEvent is true for events that are about to start or are currently running.
+func isUpcomingEvent(e jsondb.RaceEvent) bool {
+	return e.Status == jsondb.ScheduledEventStatus || e.Status == jsondb.InProgressEventStatus
+}
+
+func filterEvents(events []jsondb.RaceEvent, filter func(e jsondb.RaceEvent) bool) []jsondb.RaceEvent {
+	filtered := make([]jsondb.RaceEvent, 0, len(events))
+	for _, e := range events {
+		if filter(e) {
+			filtered = append(filtered, e)
+		}
+	}
+
+	return filtered
+}
+
+func sortEventsByDate(events []jsondb.RaceEvent) []jsondb.RaceEvent {
+	sorted := make([]jsondb.RaceEvent, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Date < sorted[j].Date
+	})
+
+	return sorted
+}
+
+// groupEventsIntoRounds sorts the events by date and groups all events on the same
+// day into one round, e.g. a sprint and the main race of a race weekend.
+func groupEventsIntoRounds(events []jsondb.RaceEvent) [][]jsondb.RaceEvent {
+	rounds := make([][]jsondb.RaceEvent, 0)
+	var currentDay time.Time
+	for _, e := range sortEventsByDate(events) {
+		orgTime := time.Unix(e.Date, 0)
+		day := time.Date(orgTime.Year(), orgTime.Month(), orgTime.Day(), 0, 0, 0, 0, time.Local)
+		if len(rounds) <= 0 || !day.Equal(currentDay) {
+			rounds = append(rounds, make([]jsondb.RaceEvent, 0))
+			currentDay = day
+		}
+
+		rounds[len(rounds)-1] = append(rounds[len(rounds)-1], e)
+	}
+
+	return rounds
+}
+
+// buildStandings ranks all drivers and teams by the points of the given events.
+// The caller is responsible to only pass events that count for the championship.
+func buildStandings(teams []jsondb.Team, events []jsondb.RaceEvent, config standingsConfig) standings {
+	driverRows := make(map[uint64]*standingsRow)
+	teamRows := make(map[uint64]*standingsRow)
+	driverOrder := make([]uint64, 0)
+	teamOrder := make([]uint64, 0)
+
+	classMode := config.ClassID != 0
+	limitedMode := classMode || config.Roster != nil
+
+	for _, t := range teams {
+		teamRows[t.ID] = &standingsRow{ID: t.ID, Name: t.Name, Results: make([]standingsResult, 0)}
+
+		for _, d := range t.Drivers {
+			driverRows[d.ID] = &standingsRow{ID: d.ID, Name: d.Name, TeamID: t.ID, Results: make([]standingsResult, 0)}
+			if d.Role == jsondb.RaceDriverRole && !limitedMode {
+				driverOrder = append(driverOrder, d.ID)
+			}
+		}
+	}
+
+	listedDrivers := make(map[uint64]bool)
+	for _, id := range config.Roster {
+		if _, ok := driverRows[id]; ok && !listedDrivers[id] {
+			driverOrder = append(driverOrder, id)
+			listedDrivers[id] = true
+		}
+	}
+
+	scoredDrivers := make(map[uint64]bool)
+	scoredTeams := make(map[uint64]bool)
+	for _, e := range sortEventsByDate(events) {
+		for _, result := range e.Results {
+			res := standingsResult{
+				EventID:   e.ID,
+				EventType: e.Type,
+				Date:      e.Date,
+				Position:  result.Position,
+				Points:    result.Points,
+			}
+			if classMode {
+				if result.ClassID != config.ClassID {
+					continue
+				}
+				res.Position = result.ClassPosition
+				res.Points = result.ClassPoints
+			}
+
+			if driver, ok := driverRows[result.DriverID]; ok {
+				driver.Points += res.Points
+				driver.Results = append(driver.Results, res)
+				scoredDrivers[result.DriverID] = true
+			}
+
+			if result.Substitute && !config.Settings.SubstitutePointsForTeam {
+				continue
+			}
+			if team, ok := teamRows[result.TeamID]; ok {
+				team.Points += res.Points
+				team.Results = append(team.Results, res)
+				scoredTeams[result.TeamID] = true
+			}
+		}
+	}
+
+	// class and division standings only list teams that competed
+	for _, t := range teams {
+		if !limitedMode || scoredTeams[t.ID] {
+			teamOrder = append(teamOrder, t.ID)
+		}
+	}
+
+	// reserve and test drivers are only listed once they took part in an event. the same
+	// goes for all drivers in class standings and drivers outside of the division roster
+	for _, t := range teams {
+		for _, d := range t.Drivers {
+			if listedDrivers[d.ID] {
+				continue
+			}
+			if (d.Role != jsondb.RaceDriverRole || limitedMode) && scoredDrivers[d.ID] {
+				driverOrder = append(driverOrder, d.ID)
+			}
+		}
+	}
+
+	result := standings{
+		Drivers: make([]*standingsRow, 0, len(driverOrder)),
+		Teams:   make([]*standingsRow, 0, len(teamOrder)),
+	}
+	for _, id := range driverOrder {
+		result.Drivers = append(result.Drivers, driverRows[id])
+	}
+	for _, id := range teamOrder {
+		result.Teams = append(result.Teams, teamRows[id])
+	}
+
+	// dropped scores only apply to drivers, teams always keep all of their points
+	for _, row := range result.Drivers {
+		row.GrossPoints = row.Points
+		if config.DropRule != nil {
+			applyDropRule(row, *config.DropRule, events)
+		}
+	}
+	for _, row := range result.Teams {
+		row.GrossPoints = row.Points
+	}
+
+	rankStandings(result.Drivers, config.Settings.TieBreakers)
+	rankStandings(result.Teams, config.Settings.TieBreakers)
+
+	return result
+}
+
+// applyDropRule flags the results of a driver that do not count toward the net points.
+// Events the driver missed count as a zero result and are the first ones to be dropped.
+func applyDropRule(row *standingsRow, rule jsondb.DropRule, events []jsondb.RaceEvent) {
+	eligible := make([]int, 0, len(row.Results))
+	for index, res := range row.Results {
+		if rule.AppliesTo(res.EventType) {
+			eligible = append(eligible, index)
+		}
+	}
+
+	eligibleEvents := 0
+	for _, e := range events {
+		if rule.AppliesTo(e.Type) {
+			eligibleEvents++
+		}
+	}
+	missed := eligibleEvents - len(eligible)
+
+	// worst results first, older results are dropped before newer ones with equal points
+	sort.SliceStable(eligible, func(i, j int) bool {
+		a, b := row.Results[eligible[i]], row.Results[eligible[j]]
+		if a.Points != b.Points {
+			return a.Points < b.Points
+		}
+		return a.Date < b.Date
+	})
+
+	dropCount := 0
+	switch rule.Mode {
+	case jsondb.DropWorstMode:
+		dropCount = int(rule.Count) - missed
+	case jsondb.CountBestMode:
+		dropCount = len(eligible) - int(rule.Count)
+	}
+
+	for i := 0; i < dropCount && i < len(eligible); i++ {
+		res := &row.Results[eligible[i]]
+		res.Dropped = true
+		row.Points -= res.Points
+	}
+}
+
+// rankStandings sorts the rows by points and applies the tie breaker chain for equal points.
+// Every row that was placed behind an entry with equal points records the rule that decided it.
+func rankStandings(rows []*standingsRow, tieBreakers []jsondb.TieBreaker) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].Points != rows[j].Points {
+			return rows[i].Points > rows[j].Points
+		}
+
+		cmp, _ := compareByTieBreakers(rows[i], rows[j], tieBreakers)
+		return cmp > 0
+	})
+
+	for index, row := range rows {
+		row.Position = uint64(index + 1)
+		row.DecidedBy = ""
+		if index == 0 {
+			continue
+		}
+
+		prev := rows[index-1]
+		if prev.Points != row.Points {
+			continue
+		}
+
+		cmp, decidedBy := compareByTieBreakers(prev, row, tieBreakers)
+		if cmp == 0 {
+			row.Position = prev.Position
+			continue
+		}
+		row.DecidedBy = decidedBy
+	}
+}
+
+// compareByTieBreakers returns a positive value if a is ahead of b, a negative value if b is
+// ahead of a and 0 if no rule could separate them. The name of the deciding rule is returned as well.
+func compareByTieBreakers(a, b *standingsRow, tieBreakers []jsondb.TieBreaker) (int, string) {
+	for _, tieBreaker := range tieBreakers {
+		if cmp := compareByTieBreaker(a, b, tieBreaker); cmp != 0 {
+			return cmp, tieBreaker.Name()
+		}
+	}
+
+	return 0, ""
+}
+
+func compareByTieBreaker(a, b *standingsRow, tieBreaker jsondb.TieBreaker) int {
+	switch tieBreaker.Rule {
+	case jsondb.MostFinishesTieBreakRule:
+		return compareUint(countFinishes(a.Results, tieBreaker.Position), countFinishes(b.Results, tieBreaker.Position))
+	case jsondb.CountbackTieBreakRule:
+		maxPosition := maxFinishPosition(a.Results)
+		if other := maxFinishPosition(b.Results); other > maxPosition {
+			maxPosition = other
+		}
+		for position := uint64(1); position <= maxPosition; position++ {
+			if cmp := compareUint(countFinishes(a.Results, position), countFinishes(b.Results, position)); cmp != 0 {
+				return cmp
+			}
+		}
+	case jsondb.BestResultTieBreakRule:
+		return comparePositions(bestPosition(a.Results), bestPosition(b.Results))
+	case jsondb.LastRaceTieBreakRule:
+		return compareLastRace(a.Results, b.Results)
+	}
+
+	return 0
+}
+
+func compareUint(a, b uint64) int {
+	if a > b {
+		return 1
+	}
+	if a < b {
+		return -1
+	}
+
+	return 0
+}
+
+// comparePositions treats 0 as "no result" which is always behind an actual finishing position.
+func comparePositions(a, b uint64) int {
+	if a == b {
+		return 0
+	}
+	if a == 0 {
+		return -1
+	}
+	if b == 0 {
+		return 1
+	}
+	if a < b {
+		return 1
+	}
+
+	return -1
+}
+
+func countFinishes(results []standingsResult, position uint64) uint64 {
+	var count uint64
+	for _, res := range results {
+		if res.Position == position {
+			count++
+		}
+	}
+
+	return count
+}
+
+func maxFinishPosition(results []standingsResult) uint64 {
+	var max uint64
+	for _, res := range results {
+		if res.Position > max {
+			max = res.Position
+		}
+	}
+
+	return max
+}
+
+func bestPosition(results []standingsResult) uint64 {
+	var best uint64
+	for _, res := range results {
+		if best == 0 || res.Position < best {
+			best = res.Position
+		}
+	}
+
+	return best
+}
+
+// compareLastRace looks at the most recent event either entry took part in and compares
+// the best position of both entries in that event.
+func compareLastRace(a, b []standingsResult) int {
+	var lastEventID uint64
+	var lastDate int64
+	found := false
+	for _, res := range append(append([]standingsResult{}, a...), b...) {
+		if !found || res.Date > lastDate || (res.Date == lastDate && res.EventID > lastEventID) {
+			lastEventID = res.EventID
+			lastDate = res.Date
+			found = true
+		}
+	}
+
+	if !found {
+		return 0
+	}
+
+	return comparePositions(bestPositionInEvent(a, lastEventID), bestPositionInEvent(b, lastEventID))
+}
+
+func bestPositionInEvent(results []standingsResult, eventID uint64) uint64 {
+	var best uint64
+	for _, res := range results {
+		if res.EventID == eventID && (best == 0 || res.Position < best) {
+			best = res.Position
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// GetEventStrategyHandler renders the stints and pit stops of every driver of an event.
+func GetEventStrategyHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		raceID, err := strconv.Atoi(ctx.Param("race_id"))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		event, err := repo.GetEvent(uint64(raceID))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		teams, err := repo.ListTeams()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read teams")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		teamNameMap, driverNameMap, err := buildNameMaps(repo)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to generate name maps")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, convertEventStrategyToResponse(*event, teams, teamNameMap, driverNameMap))
+	}
+}
+
+// GetPitStopStatsHandler aggregates the pit stops per team over all completed events matching the
+// season_id and type query parameters. Without a type qualifying sessions are left out.
+func GetPitStopStatsHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		events, ok := loadFilteredEvents(ctx, repo)
+		if !ok {
+			return
+		}
+
+		teams, err := repo.ListTeams()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read teams")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		_, driverNameMap, err := buildNameMaps(repo)
+		if err != nil {
+			logrus.WithError(err).Warn("unable to generate name maps")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, convertPitStopStatsToResponse(events, teams, driverNameMap))
+	}
+}
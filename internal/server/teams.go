@@ -25,7 +25,18 @@ func GetTeamsHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
 			return
 		}
 
-		teamsResp := convertTeamsToResponse(teams, events)
+		settings, err := repo.GetSettings()
+		if err != nil {
+			logrus.WithError(err).Warn("unable to read settings")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		teamsResp := convertTeamsToResponse(teams, filterEvents(events, isCompletedEvent), *settings)
+		if wantsCSV(ctx) {
+			respondCSV(ctx, "teams.csv", convertTeamsToCSV(teamsResp))
+			return
+		}
 
 		ctx.JSON(http.StatusOK, teamsResp)
 	}
@@ -61,6 +72,18 @@ func AddTeamHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
 			return
 		}
 
+		if err := normalizeTeamBranding(newTeam); err != nil {
+			logrus.WithError(err).Warn("invalid team branding")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		// images are only set by the upload endpoints
+		newTeam.Logo = ""
+		for index := range newTeam.Drivers {
+			newTeam.Drivers[index].Portrait = ""
+		}
+
 		if err := repo.AddTeam(newTeam); err != nil {
 			logrus.WithError(err).Warn("unable to add team")
 			ctx.AbortWithStatus(http.StatusInternalServerError)
@@ -93,13 +116,25 @@ func UpdateTeamHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
 			return
 		}
 
+		if err := normalizeTeamBranding(userInputTeam); err != nil {
+			logrus.WithError(err).Warn("invalid team branding")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
 		existing.Name = userInputTeam.Name
+		existing.ShortName = userInputTeam.ShortName
+		existing.Color = userInputTeam.Color
+		newDrivers := make([]jsondb.Driver, 0)
 		for index, driver := range userInputTeam.Drivers {
+			// portraits are only set by the upload endpoint
+			driver.Portrait = ""
 			if len(existing.Drivers) > index {
-				existing.Drivers[index].Name = driver.Name
+				driver.ID = existing.Drivers[index].ID
+				driver.Portrait = existing.Drivers[index].Portrait
+				existing.Drivers[index] = driver
 			} else {
-				ctx.AbortWithStatus(http.StatusBadRequest)
-				return
+				newDrivers = append(newDrivers, driver)
 			}
 		}
 
@@ -109,6 +144,15 @@ func UpdateTeamHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
 			return
 		}
 
+		// additional drivers extend the roster and need fresh IDs
+		for index := range newDrivers {
+			if err := repo.AddDriver(existing.ID, &newDrivers[index]); err != nil {
+				logrus.WithError(err).Warn("unable to add driver to team")
+				ctx.AbortWithStatus(http.StatusInternalServerError)
+				return
+			}
+		}
+
 		ctx.Status(http.StatusOK)
 	}
 }
@@ -131,10 +175,60 @@ func DeleteTeamHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
 	}
 }
 
+func AddDriverHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		newDriver := &jsondb.Driver{}
+
+		if err := ctx.BindJSON(newDriver); err != nil {
+			logrus.WithError(err).Warn("unable to get user input for new driver")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		if err := normalizeDriverBranding(newDriver); err != nil {
+			logrus.WithError(err).Warn("invalid driver branding")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		newDriver.Portrait = ""
+
+		teamID, err := strconv.Atoi(ctx.Param("team_id"))
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		if _, err := repo.GetTeam(uint64(teamID)); err != nil {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		if err := repo.AddDriver(uint64(teamID), newDriver); err != nil {
+			logrus.WithError(err).Warn("unable to add driver")
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		ctx.Status(http.StatusCreated)
+	}
+}
+
 func UpdateDriverHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		userInputDriver := &jsondb.Driver{}
 
+		if err := ctx.BindJSON(userInputDriver); err != nil {
+			logrus.WithError(err).Warn("unable to get user input for driver update")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		if err := normalizeDriverBranding(userInputDriver); err != nil {
+			logrus.WithError(err).Warn("invalid driver branding")
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
 		teamID, err := strconv.Atoi(ctx.Param("team_id"))
 		if err != nil {
 			ctx.AbortWithStatus(http.StatusBadRequest)
@@ -157,6 +251,8 @@ func UpdateDriverHandler(repo jsondb.JsonDatabase) gin.HandlerFunc {
 		found := false
 		for index, existingDriver := range existing.Drivers {
 			if existingDriver.ID == uint64(driverID) {
+				userInputDriver.ID = existingDriver.ID
+				userInputDriver.Portrait = existingDriver.Portrait
 				existing.Drivers[index] = *userInputDriver
 				found = true
 				break
@@ -0,0 +1,285 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+	"github.com/devnull-twitch/nyooom-backend/pkg/telemetry/f1"
+	"github.com/sirupsen/logrus"
+)
+
+// ListenF1Telemetry receives the telemetry of the F1 game on the UDP address and creates a
+// draft event for every finished race session. With a capture writer all received packets
+// are recorded so the session can be replayed later.
+func ListenF1Telemetry(ctx context.Context, repo jsondb.JsonDatabase, address string, capture *f1.CaptureWriter) error {
+	collector := f1.NewCollector(func(classification f1.Classification) {
+		event, unmatched, err := ImportF1Classification(repo, classification)
+		if err != nil {
+			logrus.WithError(err).WithField("session_uid", classification.SessionUID).Warn("unable to import f1 session")
+			return
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"event_id":  event.ID,
+			"unmatched": unmatched,
+		}).Info("created draft event from f1 telemetry")
+	})
+
+	return f1.Listen(ctx, address, func(packet []byte) {
+		if capture != nil {
+			if err := capture.Write(packet); err != nil {
+				logrus.WithError(err).Warn("unable to capture f1 packet")
+			}
+		}
+
+		if err := collector.Handle(packet); err != nil {
+			logrus.WithError(err).Debug("unable to handle f1 packet")
+		}
+	})
+}
+
+// ReplayF1Capture runs a recorded capture through the same import as the UDP listener.
+// Sessions that are known not to be races are skipped.
+func ReplayF1Capture(repo jsondb.JsonDatabase, r io.Reader) ([]*jsondb.RaceEvent, error) {
+	events := make([]*jsondb.RaceEvent, 0)
+	var importErr error
+	collector := f1.NewCollector(func(classification f1.Classification) {
+		// captures of a whole weekend contain practice and qualifying sessions as well
+		if !isF1Race(classification) {
+			logrus.WithField("session_uid", classification.SessionUID).Info("skipping f1 session that is not a race")
+			return
+		}
+
+		event, unmatched, err := ImportF1Classification(repo, classification)
+		if err != nil {
+			importErr = err
+			return
+		}
+
+		if len(unmatched) > 0 {
+			logrus.WithField("unmatched", unmatched).Warn("participants without driver")
+		}
+		events = append(events, event)
+	})
+
+	err := f1.ReadCapture(r, func(packet []byte) error {
+		if err := collector.Handle(packet); err != nil {
+			logrus.WithError(err).Debug("unable to handle f1 packet")
+		}
+		return importErr
+	})
+
+	return events, err
+}
+
+// ImportF1Classification stores the classification of a race session as a draft event.
+// Participants are matched to drivers by car number first and by name second. The names
+// of participants without a driver are returned and left out of the results.
+func ImportF1Classification(repo jsondb.JsonDatabase, classification f1.Classification) (*jsondb.RaceEvent, []string, error) {
+	if !isF1Race(classification) {
+		return nil, nil, fmt.Errorf("session type %d is not a race", classification.SessionType)
+	}
+
+	teams, err := repo.ListTeams()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	userInput, bestLaps, unmatched := buildF1EventRequest(classification, teams)
+	if len(userInput.Results) <= 0 {
+		return nil, unmatched, fmt.Errorf("no participant matched a driver")
+	}
+
+	event, err := buildRaceEvent(userInput, teams, nil)
+	if err != nil {
+		return nil, unmatched, err
+	}
+
+	for index := range event.Results {
+		event.Results[index].BestLapMS = bestLaps[event.Results[index].DriverID]
+	}
+
+	if err := repo.AddEvent(event); err != nil {
+		return nil, unmatched, err
+	}
+
+	return event, unmatched, nil
+}
+
+// isF1Race is also true if no session packet was received. The session type is unknown
+// then and the draft event lets an editor decide whether to keep it.
+func isF1Race(classification f1.Classification) bool {
+	return !classification.HasSession || classification.SessionType.IsRace()
+}
+
+func buildF1EventRequest(classification f1.Classification, teams []jsondb.Team) (*raceEventRequest, map[uint64]uint64, []string) {
+	draft := jsondb.DraftEventStatus
+	userInput := &raceEventRequest{
+		Name:       fmt.Sprintf("%s (F1 telemetry)", f1.TrackName(classification.TrackID)),
+		Date:       time.Now().Unix(),
+		Type:       jsondb.RaceEventType,
+		Status:     &draft,
+		Results:    make([]uint64, 0, len(classification.Cars)),
+		Conditions: convertF1Conditions(classification),
+	}
+
+	bestLaps := make(map[uint64]uint64)
+	gridPositions := make(map[uint64]uint64)
+	unmatched := make([]string, 0)
+	var fastestLapMS uint64
+	for _, car := range classification.Cars {
+		driverID, ok := matchF1Participant(car, teams, userInput.Results)
+		if !ok {
+			unmatched = append(unmatched, car.Name)
+			continue
+		}
+
+		switch car.ResultStatus {
+		case f1.InvalidResultStatus, f1.InactiveResultStatus:
+			continue
+		case f1.DidNotFinishResultStatus, f1.NotClassifiedResultStatus, f1.RetiredResultStatus:
+			userInput.DNF = append(userInput.DNF, driverID)
+		case f1.DisqualifiedResultStatus:
+			userInput.DSQ = append(userInput.DSQ, driverID)
+		}
+
+		userInput.Results = append(userInput.Results, driverID)
+		if car.GridPosition > 0 {
+			gridPositions[driverID] = car.GridPosition
+		}
+		if car.BestLapMS > 0 {
+			bestLaps[driverID] = car.BestLapMS
+			if fastestLapMS == 0 || car.BestLapMS < fastestLapMS {
+				fastestLapMS = car.BestLapMS
+				fastestDriverID := driverID
+				userInput.FastestLap = &fastestDriverID
+			}
+		}
+
+		userInput.Strategies = append(userInput.Strategies, convertF1Strategy(driverID, car))
+	}
+
+	userInput.StartingGrid = make([]uint64, 0, len(gridPositions))
+	for _, driverID := range userInput.Results {
+		if _, ok := gridPositions[driverID]; ok {
+			userInput.StartingGrid = append(userInput.StartingGrid, driverID)
+		}
+	}
+	sort.SliceStable(userInput.StartingGrid, func(i, j int) bool {
+		return gridPositions[userInput.StartingGrid[i]] < gridPositions[userInput.StartingGrid[j]]
+	})
+
+	return userInput, bestLaps, unmatched
+}
+
+// matchF1Participant finds the driver of a participant. A car number only counts if exactly
+// one driver uses it. Drivers that are already part of the results are skipped.
+func matchF1Participant(car f1.ClassifiedCar, teams []jsondb.Team, taken []uint64) (uint64, bool) {
+	numberMatches := make([]uint64, 0, 1)
+	var nameMatch *uint64
+	for _, t := range teams {
+		for _, d := range t.Drivers {
+			if IDisInList(taken, d.ID) {
+				continue
+			}
+			if car.RaceNumber != 0 && d.CarNumber == car.RaceNumber {
+				numberMatches = append(numberMatches, d.ID)
+			}
+			if nameMatch == nil && strings.EqualFold(strings.TrimSpace(d.Name), strings.TrimSpace(car.Name)) {
+				driverID := d.ID
+				nameMatch = &driverID
+			}
+		}
+	}
+
+	if len(numberMatches) == 1 {
+		return numberMatches[0], true
+	}
+	if nameMatch != nil {
+		return *nameMatch, true
+	}
+
+	return 0, false
+}
+
+// convertF1Strategy turns the tyre stints into pit stops. The stationary times come from
+// the pit lane visits observed in the lap data.
+func convertF1Strategy(driverID uint64, car f1.ClassifiedCar) driverStrategyRequest {
+	strategy := driverStrategyRequest{
+		DriverID: driverID,
+		Laps:     car.Laps,
+	}
+
+	if len(car.Stints) <= 0 {
+		for _, stop := range car.PitStops {
+			strategy.PitStops = append(strategy.PitStops, jsondb.PitStop{
+				Lap:              stop.Lap,
+				StationaryTimeMS: stop.StationaryTimeMS,
+			})
+		}
+		return strategy
+	}
+
+	strategy.StartCompound = convertF1Compound(car.Stints[0].Compound)
+	for index := 1; index < len(car.Stints); index++ {
+		stop := jsondb.PitStop{
+			Lap:      car.Stints[index-1].EndLap,
+			Compound: convertF1Compound(car.Stints[index].Compound),
+		}
+		if index-1 < len(car.PitStops) {
+			stop.StationaryTimeMS = car.PitStops[index-1].StationaryTimeMS
+		}
+		strategy.PitStops = append(strategy.PitStops, stop)
+	}
+
+	return strategy
+}
+
+func convertF1Compound(compound f1.VisualCompound) jsondb.TyreCompound {
+	switch compound {
+	case f1.SoftVisualCompound:
+		return jsondb.SoftTyreCompound
+	case f1.MediumVisualCompound:
+		return jsondb.MediumTyreCompound
+	case f1.HardVisualCompound:
+		return jsondb.HardTyreCompound
+	case f1.IntermediateVisualCompound:
+		return jsondb.IntermediateTyreCompound
+	case f1.WetVisualCompound:
+		return jsondb.WetTyreCompound
+	default:
+		return jsondb.UnknownTyreCompound
+	}
+}
+
+func convertF1Conditions(classification f1.Classification) *jsondb.SessionConditions {
+	if !classification.HasSession {
+		return nil
+	}
+
+	airTemperature := float64(classification.AirTemperature)
+	trackTemperature := float64(classification.TrackTemperature)
+	conditions := &jsondb.SessionConditions{
+		AirTemperature:   &airTemperature,
+		TrackTemperature: &trackTemperature,
+	}
+
+	switch classification.Weather {
+	case f1.ClearWeather, f1.LightCloudWeather, f1.OvercastWeather:
+		conditions.TrackState = jsondb.DryTrackState
+	case f1.LightRainWeather, f1.HeavyRainWeather, f1.StormWeather:
+		conditions.TrackState = jsondb.WetTrackState
+	default:
+		return conditions
+	}
+
+	// the game numbers the weather from 0 while clear weather is 1 in the database
+	conditions.Weather = []jsondb.WeatherPhase{{FromLap: 1, Weather: jsondb.Weather(classification.Weather) + 1}}
+
+	return conditions
+}
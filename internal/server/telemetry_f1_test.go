@@ -0,0 +1,146 @@
+package server
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"testing"
+
+	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+	"github.com/devnull-twitch/nyooom-backend/pkg/telemetry/f1"
+)
+
+// newTestDatabase creates a database in a temporary directory with two teams of two drivers.
+// The last driver of the second team has car number 44.
+func newTestDatabase(t *testing.T) jsondb.JsonDatabase {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	repo := jsondb.CreateFileDatabase()
+	teams := []*jsondb.Team{
+		{Name: "A", Drivers: []jsondb.Driver{{Name: "a1"}, {Name: "a2"}}},
+		{Name: "B", Drivers: []jsondb.Driver{{Name: "b1"}, {Name: "b2", CarNumber: 44}}},
+	}
+	for _, team := range teams {
+		if err := repo.AddTeam(team); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return repo
+}
+
+func TestReplayF1CaptureSkipsQualifying(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a short qualifying in clear weather followed by a race in light rain
+	capture, err := os.ReadFile(wd + "/testdata/f1_weekend.cap")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := newTestDatabase(t)
+	events, err := ReplayF1Capture(repo, bytes.NewReader(capture))
+	if err != nil {
+		t.Fatalf("replay failed: %s", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+
+	stored, err := repo.ListEvents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 1 {
+		t.Fatalf("expected 1 stored event, got %d", len(stored))
+	}
+
+	event := stored[0]
+	if event.Type != jsondb.RaceEventType || event.Status != jsondb.DraftEventStatus {
+		t.Errorf("expected a draft race, got type %d status %d", event.Type, event.Status)
+	}
+
+	// b1 won, a1 came second, the player has no driver and b2 retired
+	expectedDrivers := []uint64{2, 0, 3}
+	if len(event.Results) != len(expectedDrivers) {
+		t.Fatalf("expected %d results, got %d", len(expectedDrivers), len(event.Results))
+	}
+	for index, driverID := range expectedDrivers {
+		if event.Results[index].DriverID != driverID {
+			t.Errorf("expected driver %d at position %d, got %d", driverID, index+1, event.Results[index].DriverID)
+		}
+	}
+
+	winner := event.Results[0]
+	if winner.BestLapMS != 90500 {
+		t.Errorf("expected best lap of 90500ms, got %d", winner.BestLapMS)
+	}
+	second := event.Results[1]
+	if len(second.PitStops) != 1 || second.PitStops[0].Lap != 4 {
+		t.Errorf("expected one pit stop on lap 4, got %+v", second.PitStops)
+	}
+
+	if event.Conditions == nil {
+		t.Fatal("expected conditions")
+	}
+	if event.Conditions.TrackState != jsondb.WetTrackState {
+		t.Errorf("expected a wet track, got %d", event.Conditions.TrackState)
+	}
+	if event.Conditions.AirTemperature == nil || *event.Conditions.AirTemperature != 22 {
+		t.Errorf("expected 22 degree air temperature, got %v", event.Conditions.AirTemperature)
+	}
+}
+
+func TestReplayF1CaptureRejectsOversizedPackets(t *testing.T) {
+	capture := make([]byte, 4, 4+f1.MaxPacketSize+1)
+	binary.LittleEndian.PutUint32(capture, f1.MaxPacketSize+1)
+	capture = append(capture, make([]byte, f1.MaxPacketSize+1)...)
+
+	repo := newTestDatabase(t)
+	if _, err := ReplayF1Capture(repo, bytes.NewReader(capture)); err == nil {
+		t.Error("expected an error for a packet larger than the udp buffer")
+	}
+}
+
+func TestReplayF1CaptureWithoutSession(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a race recorded without its session packet
+	capture, err := os.ReadFile(wd + "/testdata/f1_no_session.cap")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := newTestDatabase(t)
+	events, err := ReplayF1Capture(repo, bytes.NewReader(capture))
+	if err != nil {
+		t.Fatalf("replay failed: %s", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+
+	event := events[0]
+	if event.Status != jsondb.DraftEventStatus || len(event.Results) != 3 {
+		t.Errorf("expected a draft with 3 results, got status %d with %d results", event.Status, len(event.Results))
+	}
+	if event.Name != "Unknown track (F1 telemetry)" {
+		t.Errorf("expected an unknown track, got %q", event.Name)
+	}
+	if event.Conditions != nil {
+		t.Errorf("expected no conditions without a session packet, got %+v", event.Conditions)
+	}
+}
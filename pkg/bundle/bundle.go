@@ -0,0 +1,278 @@
+// Package bundle reads and writes zip archives holding a complete league: all database files
+// and the uploaded assets together with a manifest of sha256 checksums.
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/devnull-twitch/nyooom-backend/pkg/jsondb"
+)
+
+// SchemaVersion is increased whenever the database files change in a way older servers can
+// not read. Bundles with a newer version are rejected.
+const SchemaVersion = 1
+
+const (
+	ManifestPath = "manifest.json"
+	assetDir     = "assets"
+
+	// maxManifestSize and maxTotalSize limit how much of an archive is decompressed
+	maxManifestSize = 1 << 20
+	maxTotalSize    = 512 << 20
+
+	teamsPath     = "teams.json"
+	eventsPath    = "events.json"
+	seasonsPath   = "seasons.json"
+	incidentsPath = "incidents.json"
+	reportsPath   = "reports.json"
+	aliasesPath   = "aliases.json"
+	settingsPath  = "settings.json"
+)
+
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+type Manifest struct {
+	SchemaVersion uint64 `json:"schema_version"`
+	CreatedAt     int64  `json:"created_at_unix"`
+	Files         []File `json:"files"`
+}
+
+type File struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Bundle is a verified archive. Assets are keyed by their file name.
+type Bundle struct {
+	Manifest Manifest
+	Snapshot jsondb.Snapshot
+	Assets   map[string][]byte
+}
+
+// Write creates the archive of the snapshot and the assets.
+func Write(w io.Writer, snapshot *jsondb.Snapshot, assets map[string][]byte, createdAt time.Time) error {
+	files := []struct {
+		path    string
+		content interface{}
+	}{
+		{teamsPath, snapshot.Teams},
+		{eventsPath, snapshot.Events},
+		{seasonsPath, snapshot.Seasons},
+		{incidentsPath, snapshot.Incidents},
+		{reportsPath, snapshot.Reports},
+		{aliasesPath, snapshot.Aliases},
+		{settingsPath, snapshot.Settings},
+	}
+
+	archive := zip.NewWriter(w)
+	manifest := Manifest{
+		SchemaVersion: SchemaVersion,
+		CreatedAt:     createdAt.Unix(),
+		Files:         make([]File, 0, len(files)+len(assets)),
+	}
+
+	for _, file := range files {
+		buf, err := json.MarshalIndent(file.content, "", "  ")
+		if err != nil {
+			return fmt.Errorf("unable to marshal %s: %w", file.path, err)
+		}
+		if err := writeFile(archive, &manifest, file.path, buf, createdAt); err != nil {
+			return err
+		}
+	}
+
+	assetNames := make([]string, 0, len(assets))
+	for name := range assets {
+		assetNames = append(assetNames, name)
+	}
+	sort.Strings(assetNames)
+	for _, name := range assetNames {
+		if !validAssetName(name) {
+			return fmt.Errorf("invalid asset name %q", name)
+		}
+		if err := writeFile(archive, &manifest, path.Join(assetDir, name), assets[name], createdAt); err != nil {
+			return err
+		}
+	}
+
+	manifestBuf, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to marshal manifest: %w", err)
+	}
+	entry, err := archive.CreateHeader(newFileHeader(ManifestPath, createdAt))
+	if err != nil {
+		return err
+	}
+	if _, err := entry.Write(manifestBuf); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+func newFileHeader(filePath string, modified time.Time) *zip.FileHeader {
+	return &zip.FileHeader{Name: filePath, Method: zip.Deflate, Modified: modified}
+}
+
+func writeFile(archive *zip.Writer, manifest *Manifest, filePath string, content []byte, modified time.Time) error {
+	entry, err := archive.CreateHeader(newFileHeader(filePath, modified))
+	if err != nil {
+		return fmt.Errorf("unable to add %s: %w", filePath, err)
+	}
+	if _, err := entry.Write(content); err != nil {
+		return fmt.Errorf("unable to write %s: %w", filePath, err)
+	}
+
+	checksum := sha256.Sum256(content)
+	manifest.Files = append(manifest.Files, File{
+		Path:   filePath,
+		Size:   int64(len(content)),
+		SHA256: hex.EncodeToString(checksum[:]),
+	})
+
+	return nil
+}
+
+// Read verifies the archive against its manifest. Every file has to be listed with a matching
+// size and checksum and every listed file has to exist. Archives with more than maxTotalSize
+// bytes of files are rejected before anything but the manifest is decompressed.
+func Read(data []byte) (*Bundle, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("unable to open archive: %w", err)
+	}
+
+	entries := make(map[string]*zip.File)
+	for _, entry := range archive.File {
+		if strings.HasSuffix(entry.Name, "/") {
+			continue
+		}
+		if _, ok := entries[entry.Name]; ok {
+			return nil, fmt.Errorf("%s is contained more than once", entry.Name)
+		}
+		entries[entry.Name] = entry
+	}
+
+	manifestEntry, ok := entries[ManifestPath]
+	if !ok {
+		return nil, fmt.Errorf("missing %s", ManifestPath)
+	}
+	manifestBuf, err := readEntry(manifestEntry, maxManifestSize)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &Bundle{Assets: make(map[string][]byte)}
+	if err := json.Unmarshal(manifestBuf, &bundle.Manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if bundle.Manifest.SchemaVersion == 0 || bundle.Manifest.SchemaVersion > SchemaVersion {
+		return nil, fmt.Errorf("unsupported schema version %d", bundle.Manifest.SchemaVersion)
+	}
+
+	// the sizes are part of the archive as well, so their sum has to stay below a fixed limit
+	var totalSize int64
+	for _, file := range bundle.Manifest.Files {
+		if file.Size < 0 || file.Size > maxTotalSize-totalSize {
+			return nil, fmt.Errorf("files are larger than %d bytes", int64(maxTotalSize))
+		}
+		totalSize += file.Size
+	}
+
+	contents := make(map[string][]byte)
+	for _, file := range bundle.Manifest.Files {
+		entry, ok := entries[file.Path]
+		if !ok {
+			return nil, fmt.Errorf("missing %s", file.Path)
+		}
+		if _, ok := contents[file.Path]; ok {
+			return nil, fmt.Errorf("%s is listed more than once", file.Path)
+		}
+
+		// no entry is read beyond its size in the manifest
+		content, err := readEntry(entry, file.Size)
+		if err != nil {
+			return nil, err
+		}
+		checksum := sha256.Sum256(content)
+		if int64(len(content)) != file.Size || !strings.EqualFold(hex.EncodeToString(checksum[:]), file.SHA256) {
+			return nil, fmt.Errorf("%s: %w", file.Path, ErrChecksumMismatch)
+		}
+		contents[file.Path] = content
+	}
+
+	for name := range entries {
+		if _, ok := contents[name]; !ok && name != ManifestPath {
+			return nil, fmt.Errorf("%s is not listed in the manifest", name)
+		}
+	}
+
+	bundle.Snapshot.Settings = jsondb.DefaultSettings()
+	files := map[string]interface{}{
+		teamsPath:     &bundle.Snapshot.Teams,
+		eventsPath:    &bundle.Snapshot.Events,
+		seasonsPath:   &bundle.Snapshot.Seasons,
+		incidentsPath: &bundle.Snapshot.Incidents,
+		reportsPath:   &bundle.Snapshot.Reports,
+		aliasesPath:   &bundle.Snapshot.Aliases,
+		settingsPath:  &bundle.Snapshot.Settings,
+	}
+	for filePath, target := range files {
+		content, ok := contents[filePath]
+		if !ok {
+			return nil, fmt.Errorf("missing %s", filePath)
+		}
+		if err := json.Unmarshal(content, target); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", filePath, err)
+		}
+		delete(contents, filePath)
+	}
+
+	for filePath, content := range contents {
+		dir, name := path.Split(filePath)
+		if dir != assetDir+"/" || !validAssetName(name) {
+			return nil, fmt.Errorf("unexpected file %s", filePath)
+		}
+		bundle.Assets[name] = content
+	}
+
+	return bundle, nil
+}
+
+func readEntry(entry *zip.File, maxSize int64) ([]byte, error) {
+	reader, err := entry.Open()
+	if err != nil {
+		return nil, fmt.Errorf("unable to open %s: %w", entry.Name, err)
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(io.LimitReader(reader, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", entry.Name, err)
+	}
+	if int64(len(content)) > maxSize {
+		return nil, fmt.Errorf("%s is larger than expected", entry.Name)
+	}
+
+	return content, nil
+}
+
+// validAssetName only accepts plain file names so assets can not escape the asset directory.
+func validAssetName(name string) bool {
+	return name != "" &&
+		!strings.HasPrefix(name, ".") &&
+		!strings.ContainsAny(name, `/\:`) &&
+		path.Base(name) == name
+}
@@ -1,8 +1,37 @@
 package jsondb
 
+import "fmt"
+
+type DriverRole uint
+
+const (
+	RaceDriverRole DriverRole = iota
+	ReserveDriverRole
+	TestDriverRole
+)
+
+func (r DriverRole) Name() string {
+	switch r {
+	case RaceDriverRole:
+		return "Race driver"
+	case ReserveDriverRole:
+		return "Reserve driver"
+	case TestDriverRole:
+		return "Test driver"
+	default:
+		return "Unknown"
+	}
+}
+
+// Driver branding is optional. Portrait is the file name of the uploaded image inside of the asset directory.
 type Driver struct {
-	ID   uint64 `json:"id"`
-	Name string `json:"name"`
+	ID           uint64     `json:"id"`
+	Name         string     `json:"name"`
+	Role         DriverRole `json:"role"`
+	Abbreviation string     `json:"abbreviation,omitempty"`
+	Nationality  string     `json:"nationality,omitempty"`
+	CarNumber    uint64     `json:"car_number,omitempty"`
+	Portrait     string     `json:"portrait,omitempty"`
 }
 
 type EventType uint
@@ -12,6 +41,7 @@ const (
 	SprintEventType
 	PreSeason
 	PreSeasonSprintType
+	QualifyingEventType
 )
 
 func (e EventType) Name() string {
@@ -24,6 +54,41 @@ func (e EventType) Name() string {
 		return "Pre-Season race"
 	case PreSeasonSprintType:
 		return "Pre-Season sprint"
+	case QualifyingEventType:
+		return "Qualifying"
+	default:
+		return "Unknown"
+	}
+}
+
+// EventStatus defaults to completed so events created before the calendar existed
+// keep counting for the standings. Draft events are created by importers and only count
+// once an editor marks them as completed.
+type EventStatus uint
+
+const (
+	CompletedEventStatus EventStatus = iota
+	ScheduledEventStatus
+	InProgressEventStatus
+	CancelledEventStatus
+	PostponedEventStatus
+	DraftEventStatus
+)
+
+func (s EventStatus) Name() string {
+	switch s {
+	case CompletedEventStatus:
+		return "Completed"
+	case ScheduledEventStatus:
+		return "Scheduled"
+	case InProgressEventStatus:
+		return "In progress"
+	case CancelledEventStatus:
+		return "Cancelled"
+	case PostponedEventStatus:
+		return "Postponed"
+	case DraftEventStatus:
+		return "Draft"
 	default:
 		return "Unknown"
 	}
@@ -34,19 +99,526 @@ type RaceEvent struct {
 	Name         string         `json:"name"`
 	Date         int64          `json:"date_unix"`
 	Type         EventType      `json:"race_type"`
+	Status       EventStatus    `json:"status,omitempty"`
+	SeasonID     uint64         `json:"season_id,omitempty"`
 	StartingGrid []RacePosition `json:"starting"`
 	Results      []RacePosition `json:"results"`
+	Lineups      []EventLineup  `json:"lineups,omitempty"`
+	ClassEntries []ClassEntry   `json:"class_entries,omitempty"`
+	DivisionID   uint64         `json:"division_id,omitempty"`
+
+	GridGeneration *GridGeneration    `json:"grid_generation,omitempty"`
+	Conditions     *SessionConditions `json:"conditions,omitempty"`
+
+	// ExternalID identifies the session an event was imported from so importing it again
+	// updates the event instead of creating a new one.
+	ExternalID string `json:"external_id,omitempty"`
+}
+
+type Weather uint
+
+const (
+	ClearWeather Weather = iota + 1
+	LightCloudWeather
+	OvercastWeather
+	LightRainWeather
+	HeavyRainWeather
+	StormWeather
+)
+
+func (w Weather) Name() string {
+	switch w {
+	case ClearWeather:
+		return "Clear"
+	case LightCloudWeather:
+		return "Light cloud"
+	case OvercastWeather:
+		return "Overcast"
+	case LightRainWeather:
+		return "Light rain"
+	case HeavyRainWeather:
+		return "Heavy rain"
+	case StormWeather:
+		return "Storm"
+	default:
+		return "Unknown"
+	}
+}
+
+// TrackState classifies a whole session as dry or wet. 0 means the state was not recorded.
+type TrackState uint
+
+const (
+	DryTrackState TrackState = iota + 1
+	WetTrackState
+)
+
+func (t TrackState) Name() string {
+	switch t {
+	case DryTrackState:
+		return "Dry"
+	case WetTrackState:
+		return "Wet"
+	default:
+		return "Unknown"
+	}
+}
+
+// WeatherPhase is the weather from the given lap on until the next phase starts.
+type WeatherPhase struct {
+	FromLap uint64  `json:"from_lap"`
+	Weather Weather `json:"weather"`
+}
+
+// SessionConditions of an event. Temperatures are in degree celsius and TimeOfDay
+// is the in-game time at the start of the session as HH:MM.
+type SessionConditions struct {
+	Weather          []WeatherPhase `json:"weather,omitempty"`
+	AirTemperature   *float64       `json:"air_temperature_c,omitempty"`
+	TrackTemperature *float64       `json:"track_temperature_c,omitempty"`
+	TrackState       TrackState     `json:"track_state,omitempty"`
+	TimeOfDay        string         `json:"time_of_day,omitempty"`
+}
+
+// HasWeather is true if any phase of the session had the given weather.
+func (c SessionConditions) HasWeather(weather Weather) bool {
+	for _, phase := range c.Weather {
+		if phase.Weather == weather {
+			return true
+		}
+	}
+
+	return false
+}
+
+type GridRule uint
+
+const (
+	StandingsGridRule GridRule = iota + 1
+	ReverseStandingsGridRule
+	RandomGridRule
+)
+
+func (r GridRule) Name() string {
+	switch r {
+	case StandingsGridRule:
+		return "Standings order"
+	case ReverseStandingsGridRule:
+		return "Reversed standings"
+	case RandomGridRule:
+		return "Random draw"
+	default:
+		return "Unknown"
+	}
+}
+
+// GridGeneration records how a starting grid was generated so it can be audited and
+// reproduced. StandingsEventIDs are the events the standings were calculated from.
+type GridGeneration struct {
+	Rule              GridRule `json:"rule"`
+	ReverseTopN       uint64   `json:"reverse_top_n,omitempty"`
+	Seed              int64    `json:"seed"`
+	StandingsEventIDs []uint64 `json:"standings_event_ids,omitempty"`
+	GeneratedAt       int64    `json:"generated_at_unix"`
+}
+
+// EventLineup records which drivers actually drove for a team in an event.
+// Drivers that are not race drivers of that team are treated as substitutes.
+type EventLineup struct {
+	TeamID    uint64   `json:"team_id"`
+	DriverIDs []uint64 `json:"driver_ids"`
+}
+
+type ResultStatus uint
+
+const (
+	FinishedResultStatus ResultStatus = iota
+	DNFResultStatus
+	DSQResultStatus
+	DNSResultStatus
+)
+
+func (s ResultStatus) Name() string {
+	switch s {
+	case FinishedResultStatus:
+		return "Finished"
+	case DNFResultStatus:
+		return "DNF"
+	case DSQResultStatus:
+		return "DSQ"
+	case DNSResultStatus:
+		return "DNS"
+	default:
+		return "Unknown"
+	}
 }
 
 type RacePosition struct {
-	Position uint64 `json:"position"`
-	Points   uint64 `json:"points"`
-	DriverID uint64 `json:"driver_id"`
-	TeamID   uint64 `json:"team_id"`
+	Position   uint64       `json:"position"`
+	Points     uint64       `json:"points"`
+	DriverID   uint64       `json:"driver_id"`
+	TeamID     uint64       `json:"team_id"`
+	Substitute bool         `json:"substitute,omitempty"`
+	Status     ResultStatus `json:"status,omitempty"`
+	FastestLap bool         `json:"fastest_lap,omitempty"`
+	BestLapMS  uint64       `json:"best_lap_ms,omitempty"`
+	// IncidentPoints as counted by the sim, e.g. the incidents of iRacing.
+	IncidentPoints uint64 `json:"incident_points,omitempty"`
+
+	ClassID       uint64 `json:"class_id,omitempty"`
+	ClassPosition uint64 `json:"class_position,omitempty"`
+	ClassPoints   uint64 `json:"class_points,omitempty"`
+
+	// Laps completed by the driver. Used to calculate the length of the last stint
+	Laps          uint64       `json:"laps,omitempty"`
+	StartCompound TyreCompound `json:"start_compound,omitempty"`
+	PitStops      []PitStop    `json:"pit_stops,omitempty"`
 }
 
+type TyreCompound uint
+
+const (
+	UnknownTyreCompound TyreCompound = iota
+	SoftTyreCompound
+	MediumTyreCompound
+	HardTyreCompound
+	IntermediateTyreCompound
+	WetTyreCompound
+)
+
+func (c TyreCompound) Name() string {
+	switch c {
+	case SoftTyreCompound:
+		return "Soft"
+	case MediumTyreCompound:
+		return "Medium"
+	case HardTyreCompound:
+		return "Hard"
+	case IntermediateTyreCompound:
+		return "Intermediate"
+	case WetTyreCompound:
+		return "Wet"
+	default:
+		return "Unknown"
+	}
+}
+
+// PitStop of a driver. The stationary time includes the time of a served penalty,
+// e.g. a 5 second penalty served at the stop.
+type PitStop struct {
+	Lap              uint64       `json:"lap"`
+	StationaryTimeMS uint64       `json:"stationary_time_ms"`
+	Compound         TyreCompound `json:"compound,omitempty"`
+	PenaltyServedMS  uint64       `json:"penalty_served_ms,omitempty"`
+}
+
+// ServiceTimeMS is the stationary time without the served penalty.
+func (p PitStop) ServiceTimeMS() uint64 {
+	if p.PenaltyServedMS >= p.StationaryTimeMS {
+		return 0
+	}
+
+	return p.StationaryTimeMS - p.PenaltyServedMS
+}
+
+// Team branding is optional. Logo is the file name of the uploaded image inside of the asset directory.
 type Team struct {
-	ID      uint64   `json:"id"`
-	Name    string   `json:"name"`
-	Drivers []Driver `json:"drivers"`
+	ID        uint64   `json:"id"`
+	Name      string   `json:"name"`
+	ShortName string   `json:"short_name,omitempty"`
+	Color     string   `json:"color,omitempty"`
+	Logo      string   `json:"logo,omitempty"`
+	Drivers   []Driver `json:"drivers"`
+}
+
+type DropRuleMode uint
+
+const (
+	DropWorstMode DropRuleMode = iota + 1
+	CountBestMode
+)
+
+// DropRule limits which results of a driver count toward the championship.
+// An empty EventTypes list applies the rule to all event types.
+type DropRule struct {
+	Mode       DropRuleMode `json:"mode"`
+	Count      uint64       `json:"count"`
+	EventTypes []EventType  `json:"event_types,omitempty"`
+}
+
+func (r DropRule) AppliesTo(eventType EventType) bool {
+	if len(r.EventTypes) <= 0 {
+		return true
+	}
+
+	for _, t := range r.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CarClass IDs are unique within a season and start at 1 so 0 means "no class".
+type CarClass struct {
+	ID   uint64 `json:"id"`
+	Name string `json:"name"`
+}
+
+// ClassEntry assigns a driver to a car class. Seasons hold the default assignments
+// which can be overwritten per event.
+type ClassEntry struct {
+	DriverID uint64 `json:"driver_id"`
+	ClassID  uint64 `json:"class_id"`
+}
+
+// Division IDs are unique within a season and start at 1 so 0 means "no division".
+// Tier 1 is the highest division, promotion and relegation happen between adjacent tiers.
+type Division struct {
+	ID        uint64   `json:"id"`
+	Name      string   `json:"name"`
+	Tier      uint64   `json:"tier"`
+	DriverIDs []uint64 `json:"driver_ids"`
+}
+
+type DivisionMoveType uint
+
+const (
+	PromotedMoveType DivisionMoveType = iota + 1
+	RelegatedMoveType
+)
+
+func (t DivisionMoveType) Name() string {
+	switch t {
+	case PromotedMoveType:
+		return "Promoted"
+	case RelegatedMoveType:
+		return "Relegated"
+	default:
+		return "Unknown"
+	}
+}
+
+// DivisionMove records a driver changing divisions at the end of a season.
+type DivisionMove struct {
+	DriverID       uint64           `json:"driver_id"`
+	Type           DivisionMoveType `json:"type"`
+	FinalPosition  uint64           `json:"final_position"`
+	FromSeasonID   uint64           `json:"from_season_id"`
+	FromDivisionID uint64           `json:"from_division_id"`
+	ToSeasonID     uint64           `json:"to_season_id"`
+	ToDivisionID   uint64           `json:"to_division_id"`
+	Date           int64            `json:"date_unix"`
+}
+
+// Season IDs start at 1 so events with a season ID of 0 are not part of any season.
+type Season struct {
+	ID            uint64         `json:"id"`
+	Name          string         `json:"name"`
+	DropRule      *DropRule      `json:"drop_rule,omitempty"`
+	Classes       []CarClass     `json:"classes,omitempty"`
+	ClassEntries  []ClassEntry   `json:"class_entries,omitempty"`
+	Divisions     []Division     `json:"divisions,omitempty"`
+	DivisionMoves []DivisionMove `json:"division_moves,omitempty"`
+	// NextSeasonID is set once the season has ended and points to its follow-up season.
+	NextSeasonID uint64 `json:"next_season_id,omitempty"`
+}
+
+func (s Season) GetDivision(divisionID uint64) (*Division, bool) {
+	for index := range s.Divisions {
+		if s.Divisions[index].ID == divisionID {
+			return &s.Divisions[index], true
+		}
+	}
+
+	return nil, false
+}
+
+func (s Season) HasClass(classID uint64) bool {
+	for _, class := range s.Classes {
+		if class.ID == classID {
+			return true
+		}
+	}
+
+	return false
+}
+
+type TieBreakRule uint
+
+const (
+	MostFinishesTieBreakRule TieBreakRule = iota + 1
+	CountbackTieBreakRule
+	BestResultTieBreakRule
+	LastRaceTieBreakRule
+)
+
+// TieBreaker is one step of the chain used to order entries with equal points.
+// Position is only used by MostFinishesTieBreakRule.
+type TieBreaker struct {
+	Rule     TieBreakRule `json:"rule"`
+	Position uint64       `json:"position,omitempty"`
+}
+
+func (t TieBreaker) Name() string {
+	switch t.Rule {
+	case MostFinishesTieBreakRule:
+		if t.Position == 1 {
+			return "Most wins"
+		}
+		return fmt.Sprintf("Most P%d finishes", t.Position)
+	case CountbackTieBreakRule:
+		return "Countback"
+	case BestResultTieBreakRule:
+		return "Best result"
+	case LastRaceTieBreakRule:
+		return "Best result in last race"
+	default:
+		return "Unknown"
+	}
+}
+
+type IncidentOutcome uint
+
+const (
+	PendingIncidentOutcome IncidentOutcome = iota
+	NoFurtherActionIncidentOutcome
+	WarningIncidentOutcome
+	PenaltyIncidentOutcome
+)
+
+func (o IncidentOutcome) Name() string {
+	switch o {
+	case PendingIncidentOutcome:
+		return "Under investigation"
+	case NoFurtherActionIncidentOutcome:
+		return "No further action"
+	case WarningIncidentOutcome:
+		return "Warning"
+	case PenaltyIncidentOutcome:
+		return "Penalty"
+	default:
+		return "Unknown"
+	}
+}
+
+// Incident during an event. The replay is referenced by a timestamp in seconds into the
+// broadcast, a VOD link or both. PenaltyReference points to the penalty given by the stewards.
+type Incident struct {
+	ID               uint64          `json:"id"`
+	EventID          uint64          `json:"event_id"`
+	Lap              uint64          `json:"lap,omitempty"`
+	DriverIDs        []uint64        `json:"driver_ids"`
+	Description      string          `json:"description"`
+	ReplayTimestamp  uint64          `json:"replay_timestamp_seconds,omitempty"`
+	VODURL           string          `json:"vod_url,omitempty"`
+	Outcome          IncidentOutcome `json:"outcome"`
+	PenaltyReference string          `json:"penalty_reference,omitempty"`
+}
+
+type ReportState uint
+
+const (
+	DraftReportState ReportState = iota
+	PublishedReportState
+)
+
+func (s ReportState) Name() string {
+	switch s {
+	case DraftReportState:
+		return "Draft"
+	case PublishedReportState:
+		return "Published"
+	default:
+		return "Unknown"
+	}
+}
+
+// Report about an event written by an editor. Every event has at most one report and
+// the body is stored as markdown.
+type Report struct {
+	EventID     uint64      `json:"event_id"`
+	Title       string      `json:"title"`
+	Body        string      `json:"body"`
+	Author      string      `json:"author"`
+	State       ReportState `json:"state"`
+	CreatedAt   int64       `json:"created_at"`
+	UpdatedAt   int64       `json:"updated_at"`
+	PublishedAt int64       `json:"published_at,omitempty"`
+}
+
+// Prediction of a viewer for a single event. The viewer name is stored lower case and
+// TokenHash is the sha256 of the optional token protecting the prediction against changes by others.
+type Prediction struct {
+	ID                 uint64   `json:"id"`
+	EventID            uint64   `json:"event_id"`
+	Viewer             string   `json:"viewer"`
+	TokenHash          string   `json:"token_hash,omitempty"`
+	Podium             []uint64 `json:"podium"`
+	PoleDriverID       *uint64  `json:"pole_driver_id,omitempty"`
+	FastestLapDriverID *uint64  `json:"fastest_lap_driver_id,omitempty"`
+	SubmittedAt        int64    `json:"submitted_at"`
+}
+
+// PredictionScoring are the points for a correct pick. PodiumDriver is awarded for a
+// driver on the podium that was predicted in a different podium position.
+type PredictionScoring struct {
+	PodiumExact  uint64 `json:"podium_exact"`
+	PodiumDriver uint64 `json:"podium_driver"`
+	Pole         uint64 `json:"pole"`
+	FastestLap   uint64 `json:"fastest_lap"`
+}
+
+type Settings struct {
+	SubstitutePointsForTeam bool              `json:"substitute_points_for_team"`
+	TieBreakers             []TieBreaker      `json:"tie_breakers"`
+	PredictionScoring       PredictionScoring `json:"prediction_scoring"`
+}
+
+func DefaultSettings() Settings {
+	return Settings{
+		SubstitutePointsForTeam: true,
+		TieBreakers: []TieBreaker{
+			{Rule: MostFinishesTieBreakRule, Position: 1},
+			{Rule: MostFinishesTieBreakRule, Position: 2},
+			{Rule: LastRaceTieBreakRule},
+		},
+		PredictionScoring: PredictionScoring{
+			PodiumExact:  5,
+			PodiumDriver: 2,
+			Pole:         3,
+			FastestLap:   3,
+		},
+	}
+}
+
+type AliasType uint
+
+const (
+	SteamAliasType AliasType = iota + 1
+	NameAliasType
+	IRacingAliasType
+)
+
+func (t AliasType) Name() string {
+	switch t {
+	case SteamAliasType:
+		return "Steam ID"
+	case NameAliasType:
+		return "Name"
+	case IRacingAliasType:
+		return "iRacing customer ID"
+	default:
+		return "Unknown"
+	}
+}
+
+// Alias maps an identity used by a game or results file to a driver. Name aliases
+// are stored lower case.
+type Alias struct {
+	ID       uint64    `json:"id"`
+	Type     AliasType `json:"type"`
+	Value    string    `json:"value"`
+	DriverID uint64    `json:"driver_id"`
 }
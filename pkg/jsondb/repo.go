@@ -11,22 +11,76 @@ type JsonDatabase interface {
 	AddTeam(t *Team) error
 	UpdateTeam(t *Team) error
 	DeleteTeam(id uint64) error
+	AddDriver(teamID uint64, d *Driver) error
 
 	ListEvents() ([]RaceEvent, error)
 	GetEvent(id uint64) (*RaceEvent, error)
 	AddEvent(e *RaceEvent) error
 	UpdateEvent(e *RaceEvent) error
 	DeleteEvent(id uint64) error
+
+	ListSeasons() ([]Season, error)
+	GetSeason(id uint64) (*Season, error)
+	AddSeason(s *Season) error
+	AddFollowUpSeason(s *Season, fromSeasonID uint64) error
+	UpdateSeason(s *Season) error
+	DeleteSeason(id uint64) error
+
+	ListIncidents() ([]Incident, error)
+	GetIncident(id uint64) (*Incident, error)
+	AddIncident(i *Incident) error
+	UpdateIncident(i *Incident) error
+	DeleteIncident(id uint64) error
+
+	ListReports() ([]Report, error)
+	GetReport(eventID uint64) (*Report, error)
+	SaveReport(eventID uint64, change func(r *Report)) (*Report, error)
+	DeleteReport(eventID uint64) error
+
+	ListPredictions() ([]Prediction, error)
+	AddPrediction(p *Prediction) error
+	UpdatePrediction(p *Prediction) error
+	DeletePrediction(id uint64) error
+
+	ListAliases() ([]Alias, error)
+	AddAlias(a *Alias) error
+	DeleteAlias(id uint64) error
+
+	GetSettings() (*Settings, error)
+	UpdateSettings(s *Settings) error
+
+	Snapshot() (*Snapshot, error)
+	Restore(s *Snapshot) error
+	Merge(merge func(s *Snapshot) error) error
 }
 
 type fileDatabase struct {
-	teamDb   *os.File
-	eventsDb *os.File
+	teamDb        *os.File
+	eventsDb      *os.File
+	settingsDb    *os.File
+	seasonsDb     *os.File
+	predictionsDb *os.File
+	incidentsDb   *os.File
+	reportsDb     *os.File
+	aliasesDb     *os.File
 
 	teamsReadLocker   sync.Locker
 	teamsWriteLocker  sync.Locker
 	eventsReadLocker  sync.Locker
 	eventsWriteLocker sync.Locker
+
+	settingsReadLocker     sync.Locker
+	settingsWriteLocker    sync.Locker
+	seasonsReadLocker      sync.Locker
+	seasonsWriteLocker     sync.Locker
+	predictionsReadLocker  sync.Locker
+	predictionsWriteLocker sync.Locker
+	incidentsReadLocker    sync.Locker
+	incidentsWriteLocker   sync.Locker
+	reportsReadLocker      sync.Locker
+	reportsWriteLocker     sync.Locker
+	aliasesReadLocker      sync.Locker
+	aliasesWriteLocker     sync.Locker
 }
 
 func CreateFileDatabase() JsonDatabase {
@@ -40,15 +94,69 @@ func CreateFileDatabase() JsonDatabase {
 		panic(err)
 	}
 
+	settingsFile, err := os.OpenFile("settings.json", os.O_RDWR|os.O_CREATE, os.ModePerm)
+	if err != nil {
+		panic(err)
+	}
+
+	seasonsFile, err := os.OpenFile("seasons.json", os.O_RDWR|os.O_CREATE, os.ModePerm)
+	if err != nil {
+		panic(err)
+	}
+
+	predictionsFile, err := os.OpenFile("predictions.json", os.O_RDWR|os.O_CREATE, os.ModePerm)
+	if err != nil {
+		panic(err)
+	}
+
+	incidentsFile, err := os.OpenFile("incidents.json", os.O_RDWR|os.O_CREATE, os.ModePerm)
+	if err != nil {
+		panic(err)
+	}
+
+	reportsFile, err := os.OpenFile("reports.json", os.O_RDWR|os.O_CREATE, os.ModePerm)
+	if err != nil {
+		panic(err)
+	}
+
+	aliasesFile, err := os.OpenFile("aliases.json", os.O_RDWR|os.O_CREATE, os.ModePerm)
+	if err != nil {
+		panic(err)
+	}
+
 	teamsRwMutex := &sync.RWMutex{}
 	eventsRwMutex := &sync.RWMutex{}
+	settingsRwMutex := &sync.RWMutex{}
+	seasonsRwMutex := &sync.RWMutex{}
+	predictionsRwMutex := &sync.RWMutex{}
+	incidentsRwMutex := &sync.RWMutex{}
+	reportsRwMutex := &sync.RWMutex{}
+	aliasesRwMutex := &sync.RWMutex{}
 
 	return &fileDatabase{
-		teamDb:            teamFile,
-		eventsDb:          eventsFile,
-		teamsReadLocker:   teamsRwMutex.RLocker(),
-		teamsWriteLocker:  teamsRwMutex,
-		eventsWriteLocker: eventsRwMutex.RLocker(),
-		eventsReadLocker:  eventsRwMutex,
+		teamDb:                 teamFile,
+		eventsDb:               eventsFile,
+		settingsDb:             settingsFile,
+		teamsReadLocker:        teamsRwMutex.RLocker(),
+		teamsWriteLocker:       teamsRwMutex,
+		eventsWriteLocker:      eventsRwMutex.RLocker(),
+		eventsReadLocker:       eventsRwMutex,
+		settingsReadLocker:     settingsRwMutex.RLocker(),
+		settingsWriteLocker:    settingsRwMutex,
+		seasonsDb:              seasonsFile,
+		seasonsReadLocker:      seasonsRwMutex.RLocker(),
+		seasonsWriteLocker:     seasonsRwMutex,
+		predictionsDb:          predictionsFile,
+		predictionsReadLocker:  predictionsRwMutex.RLocker(),
+		predictionsWriteLocker: predictionsRwMutex,
+		incidentsDb:            incidentsFile,
+		incidentsReadLocker:    incidentsRwMutex.RLocker(),
+		incidentsWriteLocker:   incidentsRwMutex,
+		reportsDb:              reportsFile,
+		reportsReadLocker:      reportsRwMutex.RLocker(),
+		reportsWriteLocker:     reportsRwMutex,
+		aliasesDb:              aliasesFile,
+		aliasesReadLocker:      aliasesRwMutex.RLocker(),
+		aliasesWriteLocker:     aliasesRwMutex,
 	}
 }
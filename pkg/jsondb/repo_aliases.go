@@ -0,0 +1,106 @@
+package jsondb
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+func (db *fileDatabase) ListAliases() ([]Alias, error) {
+	db.aliasesReadLocker.Lock()
+	defer db.aliasesReadLocker.Unlock()
+
+	schema, err := db.readAliases()
+	if err != nil {
+		return nil, err
+	}
+
+	return schema.Aliases, nil
+}
+
+func (db *fileDatabase) AddAlias(a *Alias) error {
+	db.aliasesWriteLocker.Lock()
+	defer db.aliasesWriteLocker.Unlock()
+
+	schema, err := db.readAliases()
+	if err != nil {
+		return err
+	}
+
+	// an identity can only belong to one driver
+	if err := schema.Add(a); err != nil {
+		return err
+	}
+
+	if err := db.writeAliases(schema); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (db *fileDatabase) DeleteAlias(id uint64) error {
+	db.aliasesWriteLocker.Lock()
+	defer db.aliasesWriteLocker.Unlock()
+
+	schema, err := db.readAliases()
+	if err != nil {
+		return err
+	}
+
+	filteredAliases := make([]Alias, 0, len(schema.Aliases))
+	for _, existingAlias := range schema.Aliases {
+		if existingAlias.ID != id {
+			filteredAliases = append(filteredAliases, existingAlias)
+		}
+	}
+
+	schema.Aliases = filteredAliases
+
+	if err := db.writeAliases(schema); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (db *fileDatabase) readAliases() (*AliasSchema, error) {
+	if _, err := db.aliasesDb.Seek(0, 0); err != nil {
+		return nil, fmt.Errorf("error resetting file cursor for aliases file: %w", err)
+	}
+
+	aliasesBuf, err := io.ReadAll(db.aliasesDb)
+	if err != nil {
+		return nil, fmt.Errorf("error reading aliases from file: %w", err)
+	}
+
+	if len(aliasesBuf) <= 0 {
+		return &AliasSchema{}, nil
+	}
+
+	schema := &AliasSchema{}
+	if err := json.Unmarshal(aliasesBuf, schema); err != nil {
+		return nil, fmt.Errorf("error unmarshaling alias json: %w", err)
+	}
+
+	return schema, nil
+}
+
+func (db *fileDatabase) writeAliases(schema *AliasSchema) error {
+	if _, err := db.aliasesDb.Seek(0, 0); err != nil {
+		return fmt.Errorf("error resetting file cursor for aliases file: %w", err)
+	}
+
+	aliasesBuf, err := json.Marshal(schema)
+	if err != nil {
+		return fmt.Errorf("unable to marshal aliases to json: %w", err)
+	}
+
+	if err := db.aliasesDb.Truncate(0); err != nil {
+		return fmt.Errorf("unable to truncate aliases file: %w", err)
+	}
+	_, err = db.aliasesDb.Write(aliasesBuf)
+	if err != nil {
+		return fmt.Errorf("unable to write aliases to file: %w", err)
+	}
+
+	return nil
+}
@@ -27,10 +27,7 @@ func (db *fileDatabase) AddEvent(e *RaceEvent) error {
 		return err
 	}
 
-	e.ID = schema.NextEventID
-	schema.NextEventID++
-
-	schema.Events = append(schema.Events, *e)
+	schema.Add(e)
 	if err := db.writeEvents(schema); err != nil {
 		return err
 	}
@@ -0,0 +1,148 @@
+package jsondb
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+func (db *fileDatabase) ListIncidents() ([]Incident, error) {
+	db.incidentsReadLocker.Lock()
+	defer db.incidentsReadLocker.Unlock()
+
+	schema, err := db.readIncidents()
+	if err != nil {
+		return nil, err
+	}
+
+	return schema.Incidents, nil
+}
+
+func (db *fileDatabase) GetIncident(id uint64) (*Incident, error) {
+	db.incidentsReadLocker.Lock()
+	defer db.incidentsReadLocker.Unlock()
+
+	schema, err := db.readIncidents()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read incidents: %w", err)
+	}
+
+	for _, existingIncident := range schema.Incidents {
+		if existingIncident.ID == id {
+			return &existingIncident, nil
+		}
+	}
+
+	return nil, fmt.Errorf("missing incident %d", id)
+}
+
+func (db *fileDatabase) AddIncident(i *Incident) error {
+	db.incidentsWriteLocker.Lock()
+	defer db.incidentsWriteLocker.Unlock()
+
+	schema, err := db.readIncidents()
+	if err != nil {
+		return err
+	}
+
+	schema.Add(i)
+	if err := db.writeIncidents(schema); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (db *fileDatabase) UpdateIncident(i *Incident) error {
+	db.incidentsWriteLocker.Lock()
+	defer db.incidentsWriteLocker.Unlock()
+
+	schema, err := db.readIncidents()
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for index, existingIncident := range schema.Incidents {
+		if existingIncident.ID == i.ID {
+			schema.Incidents[index] = *i
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("missing incident %d", i.ID)
+	}
+
+	if err := db.writeIncidents(schema); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (db *fileDatabase) DeleteIncident(id uint64) error {
+	db.incidentsWriteLocker.Lock()
+	defer db.incidentsWriteLocker.Unlock()
+
+	schema, err := db.readIncidents()
+	if err != nil {
+		return err
+	}
+
+	filteredIncidents := make([]Incident, 0, len(schema.Incidents))
+	for _, existingIncident := range schema.Incidents {
+		if existingIncident.ID != id {
+			filteredIncidents = append(filteredIncidents, existingIncident)
+		}
+	}
+
+	schema.Incidents = filteredIncidents
+
+	if err := db.writeIncidents(schema); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (db *fileDatabase) readIncidents() (*IncidentSchema, error) {
+	if _, err := db.incidentsDb.Seek(0, 0); err != nil {
+		return nil, fmt.Errorf("error resetting file cursor for incidents file: %w", err)
+	}
+
+	incidentsBuf, err := io.ReadAll(db.incidentsDb)
+	if err != nil {
+		return nil, fmt.Errorf("error reading incidents from file: %w", err)
+	}
+
+	if len(incidentsBuf) <= 0 {
+		return &IncidentSchema{}, nil
+	}
+
+	schema := &IncidentSchema{}
+	if err := json.Unmarshal(incidentsBuf, schema); err != nil {
+		return nil, fmt.Errorf("error unmarshaling incident json: %w", err)
+	}
+
+	return schema, nil
+}
+
+func (db *fileDatabase) writeIncidents(schema *IncidentSchema) error {
+	if _, err := db.incidentsDb.Seek(0, 0); err != nil {
+		return fmt.Errorf("error resetting file cursor for incidents file: %w", err)
+	}
+
+	incidentsBuf, err := json.Marshal(schema)
+	if err != nil {
+		return fmt.Errorf("unable to marshal incidents to json: %w", err)
+	}
+
+	if err := db.incidentsDb.Truncate(0); err != nil {
+		return fmt.Errorf("unable to truncate incidents file: %w", err)
+	}
+	_, err = db.incidentsDb.Write(incidentsBuf)
+	if err != nil {
+		return fmt.Errorf("unable to write incidents to file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package jsondb
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+func (db *fileDatabase) ListPredictions() ([]Prediction, error) {
+	db.predictionsReadLocker.Lock()
+	defer db.predictionsReadLocker.Unlock()
+
+	schema, err := db.readPredictions()
+	if err != nil {
+		return nil, err
+	}
+
+	return schema.Predictions, nil
+}
+
+func (db *fileDatabase) AddPrediction(p *Prediction) error {
+	db.predictionsWriteLocker.Lock()
+	defer db.predictionsWriteLocker.Unlock()
+
+	schema, err := db.readPredictions()
+	if err != nil {
+		return err
+	}
+
+	// a viewer can only have one prediction per event
+	for _, existingPrediction := range schema.Predictions {
+		if existingPrediction.EventID == p.EventID && existingPrediction.Viewer == p.Viewer {
+			return fmt.Errorf("viewer %s already has a prediction for event %d", p.Viewer, p.EventID)
+		}
+	}
+
+	p.ID = schema.NextPredictionID
+	schema.NextPredictionID++
+
+	schema.Predictions = append(schema.Predictions, *p)
+	if err := db.writePredictions(schema); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (db *fileDatabase) UpdatePrediction(p *Prediction) error {
+	db.predictionsWriteLocker.Lock()
+	defer db.predictionsWriteLocker.Unlock()
+
+	schema, err := db.readPredictions()
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for index, existingPrediction := range schema.Predictions {
+		if existingPrediction.ID == p.ID {
+			schema.Predictions[index] = *p
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("missing prediction %d", p.ID)
+	}
+
+	if err := db.writePredictions(schema); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (db *fileDatabase) DeletePrediction(id uint64) error {
+	db.predictionsWriteLocker.Lock()
+	defer db.predictionsWriteLocker.Unlock()
+
+	schema, err := db.readPredictions()
+	if err != nil {
+		return err
+	}
+
+	filteredPredictions := make([]Prediction, 0, len(schema.Predictions))
+	for _, existingPrediction := range schema.Predictions {
+		if existingPrediction.ID != id {
+			filteredPredictions = append(filteredPredictions, existingPrediction)
+		}
+	}
+
+	schema.Predictions = filteredPredictions
+
+	if err := db.writePredictions(schema); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (db *fileDatabase) readPredictions() (*PredictionSchema, error) {
+	if _, err := db.predictionsDb.Seek(0, 0); err != nil {
+		return nil, fmt.Errorf("error resetting file cursor for predictions file: %w", err)
+	}
+
+	predictionsBuf, err := io.ReadAll(db.predictionsDb)
+	if err != nil {
+		return nil, fmt.Errorf("error reading predictions from file: %w", err)
+	}
+
+	if len(predictionsBuf) <= 0 {
+		return &PredictionSchema{}, nil
+	}
+
+	schema := &PredictionSchema{}
+	if err := json.Unmarshal(predictionsBuf, schema); err != nil {
+		return nil, fmt.Errorf("error unmarshaling prediction json: %w", err)
+	}
+
+	return schema, nil
+}
+
+func (db *fileDatabase) writePredictions(schema *PredictionSchema) error {
+	if _, err := db.predictionsDb.Seek(0, 0); err != nil {
+		return fmt.Errorf("error resetting file cursor for predictions file: %w", err)
+	}
+
+	predictionsBuf, err := json.Marshal(schema)
+	if err != nil {
+		return fmt.Errorf("unable to marshal predictions to json: %w", err)
+	}
+
+	if err := db.predictionsDb.Truncate(0); err != nil {
+		return fmt.Errorf("unable to truncate predictions file: %w", err)
+	}
+	_, err = db.predictionsDb.Write(predictionsBuf)
+	if err != nil {
+		return fmt.Errorf("unable to write predictions to file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package jsondb
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+func (db *fileDatabase) ListReports() ([]Report, error) {
+	db.reportsReadLocker.Lock()
+	defer db.reportsReadLocker.Unlock()
+
+	schema, err := db.readReports()
+	if err != nil {
+		return nil, err
+	}
+
+	return schema.Reports, nil
+}
+
+func (db *fileDatabase) GetReport(eventID uint64) (*Report, error) {
+	db.reportsReadLocker.Lock()
+	defer db.reportsReadLocker.Unlock()
+
+	schema, err := db.readReports()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read reports: %w", err)
+	}
+
+	for _, existingReport := range schema.Reports {
+		if existingReport.EventID == eventID {
+			return &existingReport, nil
+		}
+	}
+
+	return nil, fmt.Errorf("missing report for event %d", eventID)
+}
+
+// SaveReport adds the report or replaces the existing report of the same event.
+// SaveReport applies the change to the report of the event while holding the lock so editors
+// saving at the same time do not overwrite each other. A new report only has its EventID set
+// when it is passed to change.
+func (db *fileDatabase) SaveReport(eventID uint64, change func(r *Report)) (*Report, error) {
+	db.reportsWriteLocker.Lock()
+	defer db.reportsWriteLocker.Unlock()
+
+	schema, err := db.readReports()
+	if err != nil {
+		return nil, err
+	}
+
+	index := -1
+	for existingIndex, existingReport := range schema.Reports {
+		if existingReport.EventID == eventID {
+			index = existingIndex
+			break
+		}
+	}
+
+	report := Report{EventID: eventID}
+	if index >= 0 {
+		report = schema.Reports[index]
+	}
+	change(&report)
+	report.EventID = eventID
+
+	if index >= 0 {
+		schema.Reports[index] = report
+	} else {
+		schema.Reports = append(schema.Reports, report)
+	}
+
+	if err := db.writeReports(schema); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (db *fileDatabase) DeleteReport(eventID uint64) error {
+	db.reportsWriteLocker.Lock()
+	defer db.reportsWriteLocker.Unlock()
+
+	schema, err := db.readReports()
+	if err != nil {
+		return err
+	}
+
+	filteredReports := make([]Report, 0, len(schema.Reports))
+	for _, existingReport := range schema.Reports {
+		if existingReport.EventID != eventID {
+			filteredReports = append(filteredReports, existingReport)
+		}
+	}
+
+	schema.Reports = filteredReports
+
+	if err := db.writeReports(schema); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (db *fileDatabase) readReports() (*ReportSchema, error) {
+	if _, err := db.reportsDb.Seek(0, 0); err != nil {
+		return nil, fmt.Errorf("error resetting file cursor for reports file: %w", err)
+	}
+
+	reportsBuf, err := io.ReadAll(db.reportsDb)
+	if err != nil {
+		return nil, fmt.Errorf("error reading reports from file: %w", err)
+	}
+
+	if len(reportsBuf) <= 0 {
+		return &ReportSchema{}, nil
+	}
+
+	schema := &ReportSchema{}
+	if err := json.Unmarshal(reportsBuf, schema); err != nil {
+		return nil, fmt.Errorf("error unmarshaling report json: %w", err)
+	}
+
+	return schema, nil
+}
+
+func (db *fileDatabase) writeReports(schema *ReportSchema) error {
+	if _, err := db.reportsDb.Seek(0, 0); err != nil {
+		return fmt.Errorf("error resetting file cursor for reports file: %w", err)
+	}
+
+	reportsBuf, err := json.Marshal(schema)
+	if err != nil {
+		return fmt.Errorf("unable to marshal reports to json: %w", err)
+	}
+
+	if err := db.reportsDb.Truncate(0); err != nil {
+		return fmt.Errorf("unable to truncate reports file: %w", err)
+	}
+	_, err = db.reportsDb.Write(reportsBuf)
+	if err != nil {
+		return fmt.Errorf("unable to write reports to file: %w", err)
+	}
+
+	return nil
+}
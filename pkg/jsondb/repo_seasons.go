@@ -0,0 +1,194 @@
+package jsondb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrSeasonAlreadyEnded is returned when a season already has a follow-up season.
+var ErrSeasonAlreadyEnded = errors.New("season already ended")
+
+func (db *fileDatabase) ListSeasons() ([]Season, error) {
+	db.seasonsReadLocker.Lock()
+	defer db.seasonsReadLocker.Unlock()
+
+	schema, err := db.readSeasons()
+	if err != nil {
+		return nil, err
+	}
+
+	return schema.Seasons, nil
+}
+
+func (db *fileDatabase) GetSeason(id uint64) (*Season, error) {
+	db.seasonsReadLocker.Lock()
+	defer db.seasonsReadLocker.Unlock()
+
+	schema, err := db.readSeasons()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read seasons: %w", err)
+	}
+
+	for _, existingSeason := range schema.Seasons {
+		if existingSeason.ID == id {
+			return &existingSeason, nil
+		}
+	}
+
+	return nil, fmt.Errorf("missing season %d", id)
+}
+
+func (db *fileDatabase) AddSeason(s *Season) error {
+	db.seasonsWriteLocker.Lock()
+	defer db.seasonsWriteLocker.Unlock()
+
+	schema, err := db.readSeasons()
+	if err != nil {
+		return err
+	}
+
+	schema.Add(s)
+	if err := db.writeSeasons(schema); err != nil {
+		return err
+	}
+	return nil
+}
+
+// AddFollowUpSeason stores the next season of an ended season together with the division
+// moves between them. The moves get the ID of the new season as target and the ended season
+// points to the new one. A season can only be ended once, even if its follow-up season is
+// deleted later.
+func (db *fileDatabase) AddFollowUpSeason(s *Season, fromSeasonID uint64) error {
+	db.seasonsWriteLocker.Lock()
+	defer db.seasonsWriteLocker.Unlock()
+
+	schema, err := db.readSeasons()
+	if err != nil {
+		return err
+	}
+
+	fromIndex := -1
+	for index, existingSeason := range schema.Seasons {
+		if existingSeason.ID == fromSeasonID {
+			fromIndex = index
+			break
+		}
+	}
+	if fromIndex < 0 {
+		return fmt.Errorf("missing season %d", fromSeasonID)
+	}
+	if schema.Seasons[fromIndex].NextSeasonID != 0 {
+		return ErrSeasonAlreadyEnded
+	}
+
+	schema.NextSeasonID++
+	s.ID = schema.NextSeasonID
+	for index := range s.DivisionMoves {
+		s.DivisionMoves[index].FromSeasonID = fromSeasonID
+		s.DivisionMoves[index].ToSeasonID = s.ID
+	}
+
+	schema.Seasons[fromIndex].NextSeasonID = s.ID
+	schema.Seasons = append(schema.Seasons, *s)
+	if err := db.writeSeasons(schema); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (db *fileDatabase) UpdateSeason(s *Season) error {
+	db.seasonsWriteLocker.Lock()
+	defer db.seasonsWriteLocker.Unlock()
+
+	schema, err := db.readSeasons()
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for index, existingSeason := range schema.Seasons {
+		if existingSeason.ID == s.ID {
+			schema.Seasons[index] = *s
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("missing season %d", s.ID)
+	}
+
+	if err := db.writeSeasons(schema); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (db *fileDatabase) DeleteSeason(id uint64) error {
+	db.seasonsWriteLocker.Lock()
+	defer db.seasonsWriteLocker.Unlock()
+
+	schema, err := db.readSeasons()
+	if err != nil {
+		return err
+	}
+
+	filteredSeasons := make([]Season, 0, len(schema.Seasons))
+	for _, existingSeason := range schema.Seasons {
+		if existingSeason.ID != id {
+			filteredSeasons = append(filteredSeasons, existingSeason)
+		}
+	}
+
+	schema.Seasons = filteredSeasons
+
+	if err := db.writeSeasons(schema); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (db *fileDatabase) readSeasons() (*SeasonSchema, error) {
+	if _, err := db.seasonsDb.Seek(0, 0); err != nil {
+		return nil, fmt.Errorf("error resetting file cursor for seasons file: %w", err)
+	}
+
+	seasonsBuf, err := io.ReadAll(db.seasonsDb)
+	if err != nil {
+		return nil, fmt.Errorf("error reading seasons from file: %w", err)
+	}
+
+	if len(seasonsBuf) <= 0 {
+		return &SeasonSchema{}, nil
+	}
+
+	schema := &SeasonSchema{}
+	if err := json.Unmarshal(seasonsBuf, schema); err != nil {
+		return nil, fmt.Errorf("erro unmarshaling season json: %w", err)
+	}
+
+	return schema, nil
+}
+
+func (db *fileDatabase) writeSeasons(schema *SeasonSchema) error {
+	if _, err := db.seasonsDb.Seek(0, 0); err != nil {
+		return fmt.Errorf("error resetting file cursor for seasons file: %w", err)
+	}
+
+	seasonsBuf, err := json.Marshal(schema)
+	if err != nil {
+		return fmt.Errorf("unable to marshal seasons to json: %w", err)
+	}
+
+	if err := db.seasonsDb.Truncate(0); err != nil {
+		return fmt.Errorf("unable to truncate seasons file: %w", err)
+	}
+	_, err = db.seasonsDb.Write(seasonsBuf)
+	if err != nil {
+		return fmt.Errorf("unable to write seasons to file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package jsondb
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+func (db *fileDatabase) GetSettings() (*Settings, error) {
+	db.settingsReadLocker.Lock()
+	defer db.settingsReadLocker.Unlock()
+
+	return db.readSettings()
+}
+
+func (db *fileDatabase) UpdateSettings(s *Settings) error {
+	db.settingsWriteLocker.Lock()
+	defer db.settingsWriteLocker.Unlock()
+
+	if err := db.writeSettings(s); err != nil {
+		return fmt.Errorf("unable to write settings: %w", err)
+	}
+
+	return nil
+}
+
+func (db *fileDatabase) readSettings() (*Settings, error) {
+	if _, err := db.settingsDb.Seek(0, 0); err != nil {
+		return nil, fmt.Errorf("error resetting file cursor for settings file: %w", err)
+	}
+
+	settingsBuf, err := io.ReadAll(db.settingsDb)
+	if err != nil {
+		return nil, fmt.Errorf("error reading settings from file: %w", err)
+	}
+
+	settings := DefaultSettings()
+	if len(settingsBuf) <= 0 {
+		return &settings, nil
+	}
+
+	if err := json.Unmarshal(settingsBuf, &settings); err != nil {
+		return nil, fmt.Errorf("erro unmarshaling settings json: %w", err)
+	}
+
+	return &settings, nil
+}
+
+func (db *fileDatabase) writeSettings(settings *Settings) error {
+	if _, err := db.settingsDb.Seek(0, 0); err != nil {
+		return fmt.Errorf("error resetting file cursor for settings file: %w", err)
+	}
+
+	settingsBuf, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("unable to marshal settings to json: %w", err)
+	}
+
+	if err := db.settingsDb.Truncate(0); err != nil {
+		return fmt.Errorf("unable to truncate settings file: %w", err)
+	}
+	_, err = db.settingsDb.Write(settingsBuf)
+	if err != nil {
+		return fmt.Errorf("unable to write settings to file: %w", err)
+	}
+
+	return nil
+}
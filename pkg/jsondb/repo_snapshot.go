@@ -0,0 +1,169 @@
+package jsondb
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrDatabaseNotEmpty is returned when restoring a snapshot would overwrite existing data.
+var ErrDatabaseNotEmpty = errors.New("database is not empty")
+
+func (db *fileDatabase) Snapshot() (*Snapshot, error) {
+	unlock := lockAll(
+		db.teamsReadLocker,
+		db.eventsReadLocker,
+		db.seasonsReadLocker,
+		db.incidentsReadLocker,
+		db.reportsReadLocker,
+		db.aliasesReadLocker,
+		db.settingsReadLocker,
+	)
+	defer unlock()
+
+	return db.readSnapshot()
+}
+
+// Restore writes the snapshot including the next IDs of every schema. It only restores into
+// a database without teams, events, seasons, incidents, reports and aliases.
+func (db *fileDatabase) Restore(s *Snapshot) error {
+	unlock := lockAll(
+		db.teamsWriteLocker,
+		db.eventsWriteLocker,
+		db.seasonsWriteLocker,
+		db.incidentsWriteLocker,
+		db.reportsWriteLocker,
+		db.aliasesWriteLocker,
+		db.settingsWriteLocker,
+	)
+	defer unlock()
+
+	existing, err := db.readSnapshot()
+	if err != nil {
+		return err
+	}
+	if len(existing.Teams.Teams) > 0 ||
+		len(existing.Events.Events) > 0 ||
+		len(existing.Seasons.Seasons) > 0 ||
+		len(existing.Incidents.Incidents) > 0 ||
+		len(existing.Reports.Reports) > 0 ||
+		len(existing.Aliases.Aliases) > 0 {
+		return ErrDatabaseNotEmpty
+	}
+
+	if err := db.writeSnapshot(s); err != nil {
+		return fmt.Errorf("unable to restore %w", err)
+	}
+
+	return nil
+}
+
+// Merge hands the current content of the database to merge and writes the changed snapshot
+// back. The database is locked for the whole time so merge can add records with the IDs of
+// the schemas. Nothing is written if merge fails.
+func (db *fileDatabase) Merge(merge func(s *Snapshot) error) error {
+	unlock := lockAll(
+		db.teamsWriteLocker,
+		db.eventsWriteLocker,
+		db.seasonsWriteLocker,
+		db.incidentsWriteLocker,
+		db.reportsWriteLocker,
+		db.aliasesWriteLocker,
+		db.settingsWriteLocker,
+	)
+	defer unlock()
+
+	snapshot, err := db.readSnapshot()
+	if err != nil {
+		return err
+	}
+	if err := merge(snapshot); err != nil {
+		return err
+	}
+
+	if err := db.writeSnapshot(snapshot); err != nil {
+		return fmt.Errorf("unable to merge %w", err)
+	}
+
+	return nil
+}
+
+func (db *fileDatabase) readSnapshot() (*Snapshot, error) {
+	teams, err := db.readTeams()
+	if err != nil {
+		return nil, err
+	}
+	events, err := db.readEvents()
+	if err != nil {
+		return nil, err
+	}
+	seasons, err := db.readSeasons()
+	if err != nil {
+		return nil, err
+	}
+	incidents, err := db.readIncidents()
+	if err != nil {
+		return nil, err
+	}
+	reports, err := db.readReports()
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := db.readAliases()
+	if err != nil {
+		return nil, err
+	}
+	settings, err := db.readSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Snapshot{
+		Teams:     *teams,
+		Events:    *events,
+		Seasons:   *seasons,
+		Incidents: *incidents,
+		Reports:   *reports,
+		Aliases:   *aliases,
+		Settings:  *settings,
+	}, nil
+}
+
+func (db *fileDatabase) writeSnapshot(s *Snapshot) error {
+	if err := db.writeTeams(&s.Teams); err != nil {
+		return fmt.Errorf("teams: %w", err)
+	}
+	if err := db.writeEvents(&s.Events); err != nil {
+		return fmt.Errorf("events: %w", err)
+	}
+	if err := db.writeSeasons(&s.Seasons); err != nil {
+		return fmt.Errorf("seasons: %w", err)
+	}
+	if err := db.writeIncidents(&s.Incidents); err != nil {
+		return fmt.Errorf("incidents: %w", err)
+	}
+	if err := db.writeReports(&s.Reports); err != nil {
+		return fmt.Errorf("reports: %w", err)
+	}
+	if err := db.writeAliases(&s.Aliases); err != nil {
+		return fmt.Errorf("aliases: %w", err)
+	}
+	if err := db.writeSettings(&s.Settings); err != nil {
+		return fmt.Errorf("settings: %w", err)
+	}
+
+	return nil
+}
+
+// lockAll locks the lockers in order and returns a function that unlocks them again.
+func lockAll(lockers ...sync.Locker) func() {
+	for _, locker := range lockers {
+		locker.Lock()
+	}
+
+	return func() {
+		for index := len(lockers) - 1; index >= 0; index-- {
+			lockers[index].Unlock()
+		}
+	}
+}
@@ -45,15 +45,7 @@ func (db *fileDatabase) AddTeam(t *Team) error {
 		return err
 	}
 
-	t.ID = schema.NextTeamID
-	schema.NextTeamID++
-
-	for index := range t.Drivers {
-		t.Drivers[index].ID = schema.NextDriverID
-		schema.NextDriverID++
-	}
-
-	schema.Teams = append(schema.Teams, *t)
+	schema.Add(t)
 	if err := db.writeTeams(schema); err != nil {
 		return err
 	}
@@ -89,6 +81,38 @@ func (db *fileDatabase) UpdateTeam(t *Team) error {
 	return nil
 }
 
+func (db *fileDatabase) AddDriver(teamID uint64, d *Driver) error {
+	db.teamsWriteLocker.Lock()
+	defer db.teamsWriteLocker.Unlock()
+
+	schema, err := db.readTeams()
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for index, extsingTeam := range schema.Teams {
+		if extsingTeam.ID == teamID {
+			d.ID = schema.NextDriverID
+			schema.NextDriverID++
+
+			schema.Teams[index].Drivers = append(schema.Teams[index].Drivers, *d)
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("cant add driver to missing team %d", teamID)
+	}
+
+	if err := db.writeTeams(schema); err != nil {
+		return fmt.Errorf("unable to write update teams: %w", err)
+	}
+
+	return nil
+}
+
 func (db *fileDatabase) DeleteTeam(id uint64) error {
 	db.teamsWriteLocker.Lock()
 	defer db.teamsWriteLocker.Unlock()
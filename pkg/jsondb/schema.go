@@ -1,12 +1,100 @@
 package jsondb
 
+import "fmt"
+
 type TeamSchema struct {
 	Teams        []Team `json:"teams"`
 	NextTeamID   uint64 `json:"next_team_id"`
 	NextDriverID uint64 `json:"next_driver_id"`
 }
 
+// Add assigns new IDs to the team and its drivers and appends it.
+func (s *TeamSchema) Add(t *Team) {
+	t.ID = s.NextTeamID
+	s.NextTeamID++
+
+	for index := range t.Drivers {
+		t.Drivers[index].ID = s.NextDriverID
+		s.NextDriverID++
+	}
+
+	s.Teams = append(s.Teams, *t)
+}
+
 type EventSchema struct {
 	Events      []RaceEvent `json:"events"`
 	NextEventID uint64      `json:"next_event_id"`
 }
+
+func (s *EventSchema) Add(e *RaceEvent) {
+	e.ID = s.NextEventID
+	s.NextEventID++
+
+	s.Events = append(s.Events, *e)
+}
+
+type SeasonSchema struct {
+	Seasons      []Season `json:"seasons"`
+	NextSeasonID uint64   `json:"next_season_id"`
+}
+
+func (s *SeasonSchema) Add(season *Season) {
+	// season IDs start at 1 as 0 marks events without a season
+	s.NextSeasonID++
+	season.ID = s.NextSeasonID
+
+	s.Seasons = append(s.Seasons, *season)
+}
+
+type PredictionSchema struct {
+	Predictions      []Prediction `json:"predictions"`
+	NextPredictionID uint64       `json:"next_prediction_id"`
+}
+
+type IncidentSchema struct {
+	Incidents      []Incident `json:"incidents"`
+	NextIncidentID uint64     `json:"next_incident_id"`
+}
+
+func (s *IncidentSchema) Add(i *Incident) {
+	i.ID = s.NextIncidentID
+	s.NextIncidentID++
+
+	s.Incidents = append(s.Incidents, *i)
+}
+
+type ReportSchema struct {
+	Reports []Report `json:"reports"`
+}
+
+type AliasSchema struct {
+	Aliases     []Alias `json:"aliases"`
+	NextAliasID uint64  `json:"next_alias_id"`
+}
+
+// Add fails if the identity already belongs to a driver.
+func (s *AliasSchema) Add(a *Alias) error {
+	for _, existingAlias := range s.Aliases {
+		if existingAlias.Type == a.Type && existingAlias.Value == a.Value {
+			return fmt.Errorf("alias %s is already used for driver %d", a.Value, existingAlias.DriverID)
+		}
+	}
+
+	a.ID = s.NextAliasID
+	s.NextAliasID++
+
+	s.Aliases = append(s.Aliases, *a)
+	return nil
+}
+
+// Snapshot is the content of all database files that make up a league. Predictions are
+// not part of it as they belong to the viewers of the original server.
+type Snapshot struct {
+	Teams     TeamSchema
+	Events    EventSchema
+	Seasons   SeasonSchema
+	Incidents IncidentSchema
+	Reports   ReportSchema
+	Aliases   AliasSchema
+	Settings  Settings
+}
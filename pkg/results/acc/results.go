@@ -0,0 +1,123 @@
+// Package acc parses the session results files written by Assetto Corsa Competizione
+// dedicated servers.
+package acc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// NoLap is the lap time the server writes for cars without a valid lap.
+const NoLap = 2147483647
+
+type SessionType string
+
+const (
+	PracticeSessionType   SessionType = "FP"
+	QualifyingSessionType SessionType = "Q"
+	RaceSessionType       SessionType = "R"
+)
+
+type Result struct {
+	SessionType      SessionType   `json:"sessionType"`
+	TrackName        string        `json:"trackName"`
+	SessionIndex     int           `json:"sessionIndex"`
+	RaceWeekendIndex int           `json:"raceWeekendIndex"`
+	ServerName       string        `json:"serverName"`
+	SessionResult    SessionResult `json:"sessionResult"`
+}
+
+type SessionResult struct {
+	BestLap          uint64            `json:"bestlap"`
+	IsWetSession     int               `json:"isWetSession"`
+	LeaderBoardLines []LeaderBoardLine `json:"leaderBoardLines"`
+}
+
+// LeaderBoardLine of a car. The lines are in classification order.
+type LeaderBoardLine struct {
+	Car                     Car    `json:"car"`
+	CurrentDriver           Driver `json:"currentDriver"`
+	Timing                  Timing `json:"timing"`
+	MissingMandatoryPitstop int    `json:"missingMandatoryPitstop"`
+}
+
+type Car struct {
+	CarID      uint64   `json:"carId"`
+	RaceNumber uint64   `json:"raceNumber"`
+	CarModel   uint64   `json:"carModel"`
+	CarGroup   string   `json:"carGroup"`
+	TeamName   string   `json:"teamName"`
+	Drivers    []Driver `json:"drivers"`
+}
+
+type Driver struct {
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+	ShortName string `json:"shortName"`
+	PlayerID  string `json:"playerId"`
+}
+
+// FullName is the first and last name separated by a space.
+func (d Driver) FullName() string {
+	return strings.TrimSpace(d.FirstName + " " + d.LastName)
+}
+
+type Timing struct {
+	LastLap   uint64 `json:"lastLap"`
+	BestLap   uint64 `json:"bestLap"`
+	TotalTime uint64 `json:"totalTime"`
+	LapCount  uint64 `json:"lapCount"`
+}
+
+// HasBestLap is false if the car did not set a valid lap.
+func (t Timing) HasBestLap() bool {
+	return t.BestLap > 0 && t.BestLap != NoLap
+}
+
+// Parse reads a results file. The server writes UTF-16 files, UTF-8 files are accepted as well.
+func Parse(data []byte) (*Result, error) {
+	data, err := decodeText(data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, fmt.Errorf("unable to parse results file: %w", err)
+	}
+
+	return result, nil
+}
+
+// decodeText converts UTF-16 input to UTF-8. Input without a byte order mark is treated as
+// UTF-16 little endian if it starts with an ASCII character followed by a zero byte.
+func decodeText(data []byte) ([]byte, error) {
+	var order binary.ByteOrder
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		order = binary.LittleEndian
+		data = data[2:]
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		order = binary.BigEndian
+		data = data[2:]
+	case len(data) >= 2 && data[0] != 0 && data[1] == 0:
+		order = binary.LittleEndian
+	default:
+		return bytes.TrimPrefix(data, []byte{0xEF, 0xBB, 0xBF}), nil
+	}
+
+	if len(data)%2 != 0 {
+		return nil, fmt.Errorf("utf-16 input has an odd length")
+	}
+
+	units := make([]uint16, 0, len(data)/2)
+	for index := 0; index < len(data); index += 2 {
+		units = append(units, order.Uint16(data[index:]))
+	}
+
+	return []byte(string(utf16.Decode(units))), nil
+}
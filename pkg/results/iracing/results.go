@@ -0,0 +1,150 @@
+// Package iracing parses the results of a subsession as exported by iRacing for league admins.
+package iracing
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type SessionKind uint
+
+const (
+	UnknownSessionKind SessionKind = iota
+	PracticeSessionKind
+	QualifyingSessionKind
+	WarmupSessionKind
+	RaceSessionKind
+)
+
+type Results struct {
+	SubsessionID   uint64    `json:"subsession_id"`
+	LeagueID       uint64    `json:"league_id"`
+	LeagueSeasonID uint64    `json:"league_season_id"`
+	StartTime      string    `json:"start_time"`
+	Track          Track     `json:"track"`
+	SessionResults []Session `json:"session_results"`
+}
+
+// StartUnix is 0 if the start time is missing or invalid.
+func (r Results) StartUnix() int64 {
+	start, err := time.Parse(time.RFC3339, r.StartTime)
+	if err != nil {
+		return 0
+	}
+
+	return start.Unix()
+}
+
+type Track struct {
+	TrackID    uint64 `json:"track_id"`
+	TrackName  string `json:"track_name"`
+	ConfigName string `json:"config_name"`
+}
+
+// Session is a simsession of the subsession. Practice has the number -2, qualifying -1
+// and the race 0 in a typical league session.
+type Session struct {
+	SimsessionNumber   int      `json:"simsession_number"`
+	SimsessionType     int      `json:"simsession_type"`
+	SimsessionTypeName string   `json:"simsession_type_name"`
+	SimsessionName     string   `json:"simsession_name"`
+	Results            []Result `json:"results"`
+}
+
+func (s Session) Kind() SessionKind {
+	name := strings.ToUpper(s.SimsessionName + " " + s.SimsessionTypeName)
+	switch {
+	case strings.Contains(name, "RACE"):
+		return RaceSessionKind
+	case strings.Contains(name, "QUALIF"):
+		return QualifyingSessionKind
+	case strings.Contains(name, "WARMUP"):
+		return WarmupSessionKind
+	case strings.Contains(name, "PRACTICE"):
+		return PracticeSessionKind
+	default:
+		return UnknownSessionKind
+	}
+}
+
+// Result of a car. Positions are 0 based and lap times are in 1/10000 seconds with -1 for
+// no time. Team events have the team in the result and the drivers in DriverResults.
+type Result struct {
+	CustID                uint64   `json:"cust_id"`
+	TeamID                uint64   `json:"team_id"`
+	DisplayName           string   `json:"display_name"`
+	FinishPosition        int      `json:"finish_position"`
+	FinishPositionInClass int      `json:"finish_position_in_class"`
+	StartingPosition      int      `json:"starting_position"`
+	LapsComplete          int      `json:"laps_complete"`
+	Incidents             int      `json:"incidents"`
+	BestLapTime           int64    `json:"best_lap_time"`
+	ReasonOutID           int      `json:"reason_out_id"`
+	ReasonOut             string   `json:"reason_out"`
+	CarClassID            uint64   `json:"car_class_id"`
+	CarClassName          string   `json:"car_class_name"`
+	CarClassShortName     string   `json:"car_class_short_name"`
+	Livery                Livery   `json:"livery"`
+	DriverResults         []Result `json:"driver_results"`
+}
+
+type Livery struct {
+	CarNumber string `json:"car_number"`
+}
+
+// Driver is the driver credited with the result. For team results this is the driver
+// that completed the most laps.
+func (r Result) Driver() Result {
+	if r.CustID != 0 || len(r.DriverResults) <= 0 {
+		return r
+	}
+
+	driver := r.DriverResults[0]
+	for _, candidate := range r.DriverResults[1:] {
+		if candidate.LapsComplete > driver.LapsComplete {
+			driver = candidate
+		}
+	}
+
+	return driver
+}
+
+// BestLapMS is 0 if the car did not set a lap time.
+func (r Result) BestLapMS() uint64 {
+	if r.BestLapTime <= 0 {
+		return 0
+	}
+
+	return uint64(r.BestLapTime / 10)
+}
+
+func (r Result) Running() bool {
+	return r.ReasonOutID == 0
+}
+
+func (r Result) Disqualified() bool {
+	return strings.EqualFold(r.ReasonOut, "Disqualified")
+}
+
+// Parse reads an exported results file. Exports wrapped into a data object like the
+// responses of the iRacing data API are accepted as well.
+func Parse(data []byte) (*Results, error) {
+	wrapper := &struct {
+		Data *Results `json:"data"`
+	}{}
+	if err := json.Unmarshal(data, wrapper); err == nil && wrapper.Data != nil && wrapper.Data.SubsessionID != 0 {
+		return wrapper.Data, nil
+	}
+
+	results := &Results{}
+	if err := json.Unmarshal(data, results); err != nil {
+		return nil, fmt.Errorf("unable to parse results file: %w", err)
+	}
+	if results.SubsessionID == 0 {
+		return nil, fmt.Errorf("results file has no subsession id")
+	}
+
+	return results, nil
+}
@@ -0,0 +1,193 @@
+// Package rf2 parses the XML results files written by rFactor 2 and the sims based on it
+// like Le Mans Ultimate.
+package rf2
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type SessionKind uint
+
+const (
+	UnknownSessionKind SessionKind = iota
+	PracticeSessionKind
+	QualifyingSessionKind
+	WarmupSessionKind
+	RaceSessionKind
+)
+
+type Results struct {
+	TrackVenue  string
+	TrackCourse string
+	TrackEvent  string
+	DateTime    int64
+	Sessions    []Session
+}
+
+// resultsFile is the layout of the file. Sessions can not be expressed as struct tags
+// because their element names differ, so every element that is no known field ends up
+// in Elements.
+type resultsFile struct {
+	RaceResults struct {
+		TrackVenue  string    `xml:"TrackVenue"`
+		TrackCourse string    `xml:"TrackCourse"`
+		TrackEvent  string    `xml:"TrackEvent"`
+		DateTime    int64     `xml:"DateTime"`
+		Elements    []Session `xml:",any"`
+	} `xml:"RaceResults"`
+}
+
+// Session elements are named after the session, e.g. Race, Qualify or Practice1.
+type Session struct {
+	XMLName           xml.Name
+	DateTime          int64    `xml:"DateTime"`
+	Laps              uint64   `xml:"Laps"`
+	MostLapsCompleted uint64   `xml:"MostLapsCompleted"`
+	Drivers           []Driver `xml:"Driver"`
+}
+
+func (s Session) Name() string {
+	return s.XMLName.Local
+}
+
+func (s Session) Kind() SessionKind {
+	name := s.Name()
+	switch {
+	case strings.HasPrefix(name, "Race"):
+		return RaceSessionKind
+	case strings.HasPrefix(name, "Qualify"):
+		return QualifyingSessionKind
+	case strings.HasPrefix(name, "Warmup"):
+		return WarmupSessionKind
+	case strings.HasPrefix(name, "Practice"):
+		return PracticeSessionKind
+	default:
+		return UnknownSessionKind
+	}
+}
+
+type FinishStatus string
+
+const (
+	FinishedNormally FinishStatus = "Finished Normally"
+	DNFFinishStatus  FinishStatus = "DNF"
+	DQFinishStatus   FinishStatus = "DQ"
+	NoneFinishStatus FinishStatus = "None"
+)
+
+type Driver struct {
+	Name          string       `xml:"Name"`
+	CarClass      string       `xml:"CarClass"`
+	CarNumber     string       `xml:"CarNumber"`
+	TeamName      string       `xml:"TeamName"`
+	GridPos       uint64       `xml:"GridPos"`
+	Position      uint64       `xml:"Position"`
+	ClassGridPos  uint64       `xml:"ClassGridPos"`
+	ClassPosition uint64       `xml:"ClassPosition"`
+	BestLapTime   string       `xml:"BestLapTime"`
+	FinishTime    string       `xml:"FinishTime"`
+	Laps          uint64       `xml:"Laps"`
+	Pitstops      uint64       `xml:"Pitstops"`
+	FinishStatus  FinishStatus `xml:"FinishStatus"`
+	DNFReason     string       `xml:"DNFReason"`
+	LapList       []Lap        `xml:"Lap"`
+}
+
+// BestLapMS is 0 if the driver did not set a valid lap.
+func (d Driver) BestLapMS() uint64 {
+	return secondsToMS(d.BestLapTime)
+}
+
+// Lap of a driver. Pit is set on laps the driver entered the pits at the end of.
+// Compounds are written as "index,name", e.g. "0,Medium".
+type Lap struct {
+	Num           uint64 `xml:"num,attr"`
+	Position      uint64 `xml:"p,attr"`
+	FrontCompound string `xml:"fcompound,attr"`
+	RearCompound  string `xml:"rcompound,attr"`
+	Pit           int    `xml:"pit,attr"`
+	Time          string `xml:",chardata"`
+}
+
+func (l Lap) CompoundName() string {
+	compound := l.FrontCompound
+	if compound == "" {
+		compound = l.RearCompound
+	}
+	if index := strings.Index(compound, ","); index >= 0 {
+		compound = compound[index+1:]
+	}
+
+	return strings.TrimSpace(compound)
+}
+
+// Parse reads a results file. Besides UTF-8 files in ISO-8859-1 or Windows-1252 are accepted.
+func Parse(data []byte) (*Results, error) {
+	file := &resultsFile{}
+	if err := newDecoder(data).Decode(file); err != nil {
+		return nil, fmt.Errorf("unable to parse results file: %w", err)
+	}
+
+	results := &Results{
+		TrackVenue:  file.RaceResults.TrackVenue,
+		TrackCourse: file.RaceResults.TrackCourse,
+		TrackEvent:  file.RaceResults.TrackEvent,
+		DateTime:    file.RaceResults.DateTime,
+	}
+	for _, session := range file.RaceResults.Elements {
+		if session.Kind() != UnknownSessionKind && len(session.Drivers) > 0 {
+			results.Sessions = append(results.Sessions, session)
+		}
+	}
+
+	if len(results.Sessions) <= 0 {
+		return nil, fmt.Errorf("results file contains no session")
+	}
+
+	return results, nil
+}
+
+func newDecoder(data []byte) *xml.Decoder {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		switch strings.ToLower(charset) {
+		case "iso-8859-1", "latin1", "windows-1252":
+			return latin1Reader(input)
+		default:
+			return nil, fmt.Errorf("unsupported charset %s", charset)
+		}
+	}
+
+	return decoder
+}
+
+// latin1Reader maps every byte to the code point of the same value. The differences of
+// Windows-1252 only affect punctuation that does not matter for results.
+func latin1Reader(input io.Reader) (io.Reader, error) {
+	buf, err := io.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+
+	runes := make([]rune, 0, len(buf))
+	for _, b := range buf {
+		runes = append(runes, rune(b))
+	}
+
+	return strings.NewReader(string(runes)), nil
+}
+
+func secondsToMS(seconds string) uint64 {
+	value, err := strconv.ParseFloat(strings.TrimSpace(seconds), 64)
+	if err != nil || value <= 0 {
+		return 0
+	}
+
+	return uint64(math.Round(value * 1000))
+}
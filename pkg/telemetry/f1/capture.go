@@ -0,0 +1,58 @@
+package f1
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// A capture file stores every received packet with a little endian uint32 length prefix
+// so sessions can be replayed without a running game.
+
+// CaptureWriter appends packets to a capture file.
+type CaptureWriter struct {
+	w io.Writer
+}
+
+func NewCaptureWriter(w io.Writer) *CaptureWriter {
+	return &CaptureWriter{w: w}
+}
+
+func (c *CaptureWriter) Write(packet []byte) error {
+	prefix := make([]byte, 4)
+	binary.LittleEndian.PutUint32(prefix, uint32(len(packet)))
+	if _, err := c.w.Write(prefix); err != nil {
+		return err
+	}
+
+	_, err := c.w.Write(packet)
+	return err
+}
+
+// ReadCapture calls handle for every packet of the capture in the order they were received.
+func ReadCapture(r io.Reader, handle func(packet []byte) error) error {
+	prefix := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(r, prefix); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("unable to read packet length: %w", err)
+		}
+
+		length := binary.LittleEndian.Uint32(prefix)
+		if length > MaxPacketSize {
+			return fmt.Errorf("packet length %d exceeds %d bytes", length, MaxPacketSize)
+		}
+
+		packet := make([]byte, length)
+		if _, err := io.ReadFull(r, packet); err != nil {
+			return fmt.Errorf("unable to read packet: %w", err)
+		}
+
+		if err := handle(packet); err != nil {
+			return err
+		}
+	}
+}
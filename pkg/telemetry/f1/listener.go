@@ -0,0 +1,33 @@
+package f1
+
+import (
+	"context"
+	"net"
+)
+
+// Listen receives UDP packets on the address until the context is done. The packet
+// passed to handle is only valid until handle returns.
+func Listen(ctx context.Context, address string, handle func(packet []byte)) error {
+	conn, err := net.ListenPacket("udp", address)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	buf := make([]byte, MaxPacketSize)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		handle(buf[:n])
+	}
+}
@@ -0,0 +1,258 @@
+// Package f1 decodes the UDP telemetry of the F1 23 game. Only the packets needed to
+// classify a session are decoded, every other packet is ignored.
+package f1
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	PacketFormat = 2023
+	MaxCars      = 22
+	// MaxPacketSize is more than the largest packet the game sends.
+	MaxPacketSize = 2048
+)
+
+type PacketID uint8
+
+const (
+	MotionPacketID PacketID = iota
+	SessionPacketID
+	LapDataPacketID
+	EventPacketID
+	ParticipantsPacketID
+	CarSetupsPacketID
+	CarTelemetryPacketID
+	CarStatusPacketID
+	FinalClassificationPacketID
+)
+
+type SessionType uint8
+
+const (
+	UnknownSessionType SessionType = iota
+	Practice1SessionType
+	Practice2SessionType
+	Practice3SessionType
+	ShortPracticeSessionType
+	Qualifying1SessionType
+	Qualifying2SessionType
+	Qualifying3SessionType
+	ShortQualifyingSessionType
+	OneShotQualifyingSessionType
+	RaceSessionType
+	Race2SessionType
+	Race3SessionType
+	TimeTrialSessionType
+)
+
+func (t SessionType) IsRace() bool {
+	return t == RaceSessionType || t == Race2SessionType || t == Race3SessionType
+}
+
+// Weather as sent by the game. 0 is clear and 5 is storm.
+type Weather uint8
+
+const (
+	ClearWeather Weather = iota
+	LightCloudWeather
+	OvercastWeather
+	LightRainWeather
+	HeavyRainWeather
+	StormWeather
+)
+
+type ResultStatus uint8
+
+const (
+	InvalidResultStatus ResultStatus = iota
+	InactiveResultStatus
+	ActiveResultStatus
+	FinishedResultStatus
+	DidNotFinishResultStatus
+	DisqualifiedResultStatus
+	NotClassifiedResultStatus
+	RetiredResultStatus
+)
+
+// VisualCompound is the tyre compound shown to the player. The actual compounds
+// (C1 to C5) differ between tracks so only the visual one is used.
+type VisualCompound uint8
+
+const (
+	SoftVisualCompound         VisualCompound = 16
+	MediumVisualCompound       VisualCompound = 17
+	HardVisualCompound         VisualCompound = 18
+	IntermediateVisualCompound VisualCompound = 7
+	WetVisualCompound          VisualCompound = 8
+)
+
+// PacketHeader is sent in front of every packet. 29 bytes.
+type PacketHeader struct {
+	PacketFormat            uint16
+	GameYear                uint8
+	GameMajorVersion        uint8
+	GameMinorVersion        uint8
+	PacketVersion           uint8
+	PacketID                PacketID
+	SessionUID              uint64
+	SessionTime             float32
+	FrameIdentifier         uint32
+	OverallFrameIdentifier  uint32
+	PlayerCarIndex          uint8
+	SecondaryPlayerCarIndex uint8
+}
+
+// SessionData contains the leading fields of the session packet. The remaining fields
+// (marshal zones, forecast, assists) are not decoded.
+type SessionData struct {
+	Weather          Weather
+	TrackTemperature int8
+	AirTemperature   int8
+	TotalLaps        uint8
+	TrackLength      uint16
+	SessionType      SessionType
+	TrackID          int8
+}
+
+// LapData of a single car. 50 bytes.
+type LapData struct {
+	LastLapTimeInMS             uint32
+	CurrentLapTimeInMS          uint32
+	Sector1TimeInMS             uint16
+	Sector1TimeMinutes          uint8
+	Sector2TimeInMS             uint16
+	Sector2TimeMinutes          uint8
+	DeltaToCarInFrontInMS       uint16
+	DeltaToRaceLeaderInMS       uint16
+	LapDistance                 float32
+	TotalDistance               float32
+	SafetyCarDelta              float32
+	CarPosition                 uint8
+	CurrentLapNum               uint8
+	PitStatus                   uint8
+	NumPitStops                 uint8
+	Sector                      uint8
+	CurrentLapInvalid           uint8
+	Penalties                   uint8
+	TotalWarnings               uint8
+	CornerCuttingWarnings       uint8
+	NumUnservedDriveThroughPens uint8
+	NumUnservedStopGoPens       uint8
+	GridPosition                uint8
+	DriverStatus                uint8
+	ResultStatus                ResultStatus
+	PitLaneTimerActive          uint8
+	PitLaneTimeInLaneInMS       uint16
+	PitStopTimerInMS            uint16
+	PitStopShouldServePen       uint8
+}
+
+// ParticipantData of a single car. 58 bytes.
+type ParticipantData struct {
+	AIControlled    uint8
+	DriverID        uint8
+	NetworkID       uint8
+	TeamID          uint8
+	MyTeam          uint8
+	RaceNumber      uint8
+	Nationality     uint8
+	Name            [48]byte
+	YourTelemetry   uint8
+	ShowOnlineNames uint8
+	Platform        uint8
+}
+
+// DriverName is the zero terminated name of the participant.
+func (p ParticipantData) DriverName() string {
+	name := p.Name[:]
+	if end := bytes.IndexByte(name, 0); end >= 0 {
+		name = name[:end]
+	}
+
+	return string(name)
+}
+
+type ParticipantsData struct {
+	NumActiveCars uint8
+	Participants  [MaxCars]ParticipantData
+}
+
+// FinalClassificationData of a single car. 45 bytes.
+type FinalClassificationData struct {
+	Position          uint8
+	NumLaps           uint8
+	GridPosition      uint8
+	Points            uint8
+	NumPitStops       uint8
+	ResultStatus      ResultStatus
+	BestLapTimeInMS   uint32
+	TotalRaceTime     float64
+	PenaltiesTime     uint8
+	NumPenalties      uint8
+	NumTyreStints     uint8
+	TyreStintsActual  [8]uint8
+	TyreStintsVisual  [8]VisualCompound
+	TyreStintsEndLaps [8]uint8
+}
+
+type FinalClassification struct {
+	NumCars        uint8
+	Classification [MaxCars]FinalClassificationData
+}
+
+// DecodeHeader reads the header of a packet and checks that the packet is in the F1 23 format.
+func DecodeHeader(packet []byte) (PacketHeader, error) {
+	header := PacketHeader{}
+	if err := decode(packet, &header); err != nil {
+		return header, fmt.Errorf("unable to decode packet header: %w", err)
+	}
+	if header.PacketFormat != PacketFormat {
+		return header, fmt.Errorf("unsupported packet format %d", header.PacketFormat)
+	}
+
+	return header, nil
+}
+
+func DecodeSession(packet []byte) (SessionData, error) {
+	data := SessionData{}
+	err := decodeBody(packet, &data)
+	return data, err
+}
+
+func DecodeLapData(packet []byte) ([MaxCars]LapData, error) {
+	data := [MaxCars]LapData{}
+	err := decodeBody(packet, &data)
+	return data, err
+}
+
+func DecodeParticipants(packet []byte) (ParticipantsData, error) {
+	data := ParticipantsData{}
+	err := decodeBody(packet, &data)
+	return data, err
+}
+
+func DecodeFinalClassification(packet []byte) (FinalClassification, error) {
+	data := FinalClassification{}
+	err := decodeBody(packet, &data)
+	return data, err
+}
+
+func decodeBody(packet []byte, data interface{}) error {
+	headerSize := binary.Size(PacketHeader{})
+	if len(packet) < headerSize {
+		return fmt.Errorf("packet of %d bytes is shorter than the header", len(packet))
+	}
+
+	return decode(packet[headerSize:], data)
+}
+
+func decode(buf []byte, data interface{}) error {
+	if size := binary.Size(data); len(buf) < size {
+		return fmt.Errorf("expected at least %d bytes but got %d", size, len(buf))
+	}
+
+	return binary.Read(bytes.NewReader(buf), binary.LittleEndian, data)
+}
@@ -0,0 +1,220 @@
+package f1
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Stint of a car on one set of tyres. EndLap is the last lap driven on the set.
+type Stint struct {
+	Compound VisualCompound
+	EndLap   uint64
+}
+
+// PitStop observed from the lap data while the car was in the pit lane.
+// StationaryTimeMS includes the time of a penalty served at the stop.
+type PitStop struct {
+	Lap              uint64
+	StationaryTimeMS uint64
+}
+
+type ClassifiedCar struct {
+	Name         string
+	RaceNumber   uint64
+	AIControlled bool
+	Position     uint64
+	GridPosition uint64
+	Laps         uint64
+	ResultStatus ResultStatus
+	BestLapMS    uint64
+	Stints       []Stint
+	PitStops     []PitStop
+}
+
+// Classification is the final result of a session together with the session conditions.
+// Cars are sorted by their classified position.
+type Classification struct {
+	SessionUID uint64
+	// HasSession is false if no session packet was received. Session type, track and
+	// conditions are unknown then.
+	HasSession       bool
+	SessionType      SessionType
+	TrackID          int8
+	TotalLaps        uint64
+	Weather          Weather
+	AirTemperature   int8
+	TrackTemperature int8
+	Cars             []ClassifiedCar
+}
+
+type pitLaneVisit struct {
+	active       bool
+	lap          uint64
+	stationaryMS uint64
+}
+
+// Collector follows the packets of a session and emits the classification once the
+// final classification packet arrives. A new session UID resets the collected state.
+type Collector struct {
+	onClassification func(Classification)
+
+	sessionUID   uint64
+	session      *SessionData
+	participants *ParticipantsData
+	visits       [MaxCars]pitLaneVisit
+	pitStops     [MaxCars][]PitStop
+	classified   bool
+}
+
+func NewCollector(onClassification func(Classification)) *Collector {
+	return &Collector{onClassification: onClassification}
+}
+
+// Handle decodes a single UDP packet. Packets of other types than session, participants,
+// lap data and final classification are ignored.
+func (c *Collector) Handle(packet []byte) error {
+	header, err := DecodeHeader(packet)
+	if err != nil {
+		return err
+	}
+
+	if header.SessionUID != c.sessionUID {
+		c.reset(header.SessionUID)
+	}
+
+	switch header.PacketID {
+	case SessionPacketID:
+		session, err := DecodeSession(packet)
+		if err != nil {
+			return fmt.Errorf("unable to decode session packet: %w", err)
+		}
+		c.session = &session
+	case ParticipantsPacketID:
+		participants, err := DecodeParticipants(packet)
+		if err != nil {
+			return fmt.Errorf("unable to decode participants packet: %w", err)
+		}
+		c.participants = &participants
+	case LapDataPacketID:
+		lapData, err := DecodeLapData(packet)
+		if err != nil {
+			return fmt.Errorf("unable to decode lap data packet: %w", err)
+		}
+		c.trackPitStops(lapData)
+	case FinalClassificationPacketID:
+		// the game sends the final classification multiple times
+		if c.classified {
+			return nil
+		}
+
+		final, err := DecodeFinalClassification(packet)
+		if err != nil {
+			return fmt.Errorf("unable to decode final classification packet: %w", err)
+		}
+		if c.participants == nil {
+			return fmt.Errorf("final classification of session %d without participants", c.sessionUID)
+		}
+
+		c.classified = true
+		c.onClassification(c.buildClassification(final))
+	}
+
+	return nil
+}
+
+func (c *Collector) reset(sessionUID uint64) {
+	c.sessionUID = sessionUID
+	c.session = nil
+	c.participants = nil
+	c.visits = [MaxCars]pitLaneVisit{}
+	c.pitStops = [MaxCars][]PitStop{}
+	c.classified = false
+}
+
+// trackPitStops records a stop whenever a car leaves the pit lane. The longest stop timer
+// seen during the visit is the stationary time.
+func (c *Collector) trackPitStops(lapData [MaxCars]LapData) {
+	for index, car := range lapData {
+		visit := &c.visits[index]
+		if car.PitLaneTimerActive == 1 {
+			if !visit.active {
+				*visit = pitLaneVisit{active: true, lap: uint64(car.CurrentLapNum)}
+			}
+			if uint64(car.PitStopTimerInMS) > visit.stationaryMS {
+				visit.stationaryMS = uint64(car.PitStopTimerInMS)
+			}
+			continue
+		}
+
+		if visit.active {
+			// drive throughs and pit lane starts without a stop are no pit stops
+			if visit.stationaryMS > 0 {
+				c.pitStops[index] = append(c.pitStops[index], PitStop{
+					Lap:              visit.lap,
+					StationaryTimeMS: visit.stationaryMS,
+				})
+			}
+			*visit = pitLaneVisit{}
+		}
+	}
+}
+
+func (c *Collector) buildClassification(final FinalClassification) Classification {
+	// the game uses -1 for an unknown track
+	classification := Classification{SessionUID: c.sessionUID, TrackID: -1}
+	if c.session != nil {
+		classification.HasSession = true
+		classification.SessionType = c.session.SessionType
+		classification.TrackID = c.session.TrackID
+		classification.TotalLaps = uint64(c.session.TotalLaps)
+		classification.Weather = c.session.Weather
+		classification.AirTemperature = c.session.AirTemperature
+		classification.TrackTemperature = c.session.TrackTemperature
+	}
+
+	numCars := int(final.NumCars)
+	if numCars > MaxCars {
+		numCars = MaxCars
+	}
+
+	cars := make([]ClassifiedCar, 0, numCars)
+	for index := 0; index < numCars; index++ {
+		data := final.Classification[index]
+		participant := c.participants.Participants[index]
+		if data.Position == 0 {
+			continue
+		}
+
+		car := ClassifiedCar{
+			Name:         participant.DriverName(),
+			RaceNumber:   uint64(participant.RaceNumber),
+			AIControlled: participant.AIControlled == 1,
+			Position:     uint64(data.Position),
+			GridPosition: uint64(data.GridPosition),
+			Laps:         uint64(data.NumLaps),
+			ResultStatus: data.ResultStatus,
+			BestLapMS:    uint64(data.BestLapTimeInMS),
+			PitStops:     c.pitStops[index],
+		}
+
+		numStints := int(data.NumTyreStints)
+		if numStints > len(data.TyreStintsVisual) {
+			numStints = len(data.TyreStintsVisual)
+		}
+		for stint := 0; stint < numStints; stint++ {
+			car.Stints = append(car.Stints, Stint{
+				Compound: data.TyreStintsVisual[stint],
+				EndLap:   uint64(data.TyreStintsEndLaps[stint]),
+			})
+		}
+
+		cars = append(cars, car)
+	}
+
+	sort.Slice(cars, func(i, j int) bool {
+		return cars[i].Position < cars[j].Position
+	})
+	classification.Cars = cars
+
+	return classification
+}